@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// StatementSummary is a statement still running or queued on a warehouse
+type StatementSummary struct {
+	StatementID     string
+	StatementText   string
+	ExecutionStatus string
+	ExecutedBy      string
+	StartTime       time.Time
+	// Elapsed is the time since StartTime when the list was taken
+	Elapsed time.Duration
+}
+
+// ListActiveStatements returns the warehouse's RUNNING and QUEUED statements from
+// system.query.history, longest-running first, so an operator can pick the worst offenders and
+// pass their IDs to CancelStatement. The table lags live activity, so very new statements may be
+// missing and finished ones may briefly still appear as running; CancelStatement on a finished
+// statement is harmless.
+func (c *DatabricksRESTClient) ListActiveStatements(ctx context.Context, warehouseID string) ([]StatementSummary, error) {
+	query := `SELECT statement_id, statement_text, execution_status, executed_by, start_time
+		FROM system.query.history
+		WHERE compute.warehouse_id = :warehouse_id AND execution_status IN ('RUNNING', 'QUEUED')
+		ORDER BY start_time ASC`
+
+	resultSchema, rows, err := c.query(ctx, query, StatementParameter{Name: "warehouse_id", Value: warehouseID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list active statements on warehouse %s: %w", warehouseID, err)
+	}
+
+	now := time.Now()
+	index := columnIndexes(resultSchema)
+	active := make([]StatementSummary, 0, len(rows))
+	for _, row := range rows {
+		r := namedRow{index: index, row: row}
+		s := StatementSummary{
+			StatementID:     r.String("statement_id"),
+			StatementText:   r.String("statement_text"),
+			ExecutionStatus: r.String("execution_status"),
+			ExecutedBy:      r.String("executed_by"),
+		}
+		if s.StartTime, err = r.Time("start_time"); err != nil {
+			return nil, err
+		}
+		if !s.StartTime.IsZero() {
+			s.Elapsed = now.Sub(s.StartTime)
+		}
+		active = append(active, s)
+	}
+	return active, nil
+}
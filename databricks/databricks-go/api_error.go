@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// APIError is returned for every non-2xx response from the REST API. ErrorCode and Message come
+// from the JSON error body; a body that is not JSON is kept verbatim in Message.
+type APIError struct {
+	StatusCode int
+	ErrorCode  string
+	Message    string
+}
+
+func newAPIError(status int, body []byte) *APIError {
+	apiErr := &APIError{StatusCode: status}
+	var parsed struct {
+		ErrorCode string `json:"error_code"`
+		Message   string `json:"message"`
+	}
+	if json.Unmarshal(body, &parsed) == nil && (parsed.ErrorCode != "" || parsed.Message != "") {
+		apiErr.ErrorCode = parsed.ErrorCode
+		apiErr.Message = parsed.Message
+	} else {
+		apiErr.Message = string(body)
+	}
+	return apiErr
+}
+
+func (e *APIError) Error() string {
+	if e.ErrorCode == "" {
+		return fmt.Sprintf("API error (status %d): %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("API error (status %d, %s): %s", e.StatusCode, e.ErrorCode, e.Message)
+}
+
+// IsRetryable reports whether the request may succeed if sent again: a 429, any 5xx other than
+// 501 Not Implemented, or an error code the API uses for transient conditions
+func (e *APIError) IsRetryable() bool {
+	if e.StatusCode == http.StatusTooManyRequests || (e.StatusCode >= 500 && e.StatusCode != http.StatusNotImplemented) {
+		return true
+	}
+	switch e.ErrorCode {
+	case "TEMPORARILY_UNAVAILABLE", "RESOURCE_EXHAUSTED", "REQUEST_LIMIT_EXCEEDED":
+		return true
+	}
+	return false
+}
+
+// IsAuth reports whether the credentials were rejected (401) or lack permission (403)
+func (e *APIError) IsAuth() bool {
+	return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+}
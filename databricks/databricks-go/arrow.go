@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/ipc"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+)
+
+// ExecuteStatementArrow runs a statement with the ARROW_STREAM format and returns the decoded
+// record batches from every chunk. TimingInfo.BytesDownloaded reports the Arrow payload size.
+// Callers must Release each returned record.
+func (c *DatabricksRESTClient) ExecuteStatementArrow(ctx context.Context, statement string) ([]arrow.Record, *TimingInfo, error) {
+	reqBody := c.newStatementRequest(statement)
+	// ARROW_STREAM is only served through presigned links, never inline
+	reqBody.Disposition = DispositionExternalLinks
+	reqBody.Format = FormatArrowStream
+
+	timing, resp, err := c.executeStatement(ctx, reqBody)
+	if err != nil {
+		return nil, timing, err
+	}
+
+	ctx, transfer := withTransferStats(ctx)
+	defer transfer.applyTo(timing)
+
+	var records []arrow.Record
+	err = c.forEachArrowBatch(ctx, resp, func(record arrow.Record) error {
+		record.Retain()
+		records = append(records, record)
+		return nil
+	})
+	if err != nil {
+		releaseRecords(records)
+		return nil, timing, err
+	}
+	return records, timing, nil
+}
+
+// forEachArrowBatch downloads each ARROW_STREAM chunk in order and calls fn with its record
+// batches. A record is only valid during the call; fn must Retain it to keep it.
+func (c *DatabricksRESTClient) forEachArrowBatch(ctx context.Context, resp *StatementExecutionResponse, fn func(arrow.Record) error) error {
+	return c.walkChunks(ctx, resp.StatementID, resp.Manifest, &resp.Result, func(chunk *ResultData) error {
+		for _, link := range chunk.ExternalLinks {
+			body, err := c.fetchExternalLink(ctx, link)
+			if err != nil {
+				return err
+			}
+
+			batches, err := decodeArrowStream(body)
+			if err != nil {
+				return fmt.Errorf("failed to decode Arrow chunk %d of statement %s: %w", link.ChunkIndex, resp.StatementID, err)
+			}
+			for i, batch := range batches {
+				if err := fn(batch); err != nil {
+					releaseRecords(batches[i:])
+					return err
+				}
+				batch.Release()
+			}
+		}
+		return nil
+	})
+}
+
+// decodeArrowStream reads every record batch from an Arrow IPC stream
+func decodeArrowStream(data []byte) ([]arrow.Record, error) {
+	reader, err := ipc.NewReader(bytes.NewReader(data), ipc.WithAllocator(memory.DefaultAllocator))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Release()
+
+	var records []arrow.Record
+	for reader.Next() {
+		// The reader reuses its current record, so keep our own reference
+		record := reader.Record()
+		record.Retain()
+		records = append(records, record)
+	}
+	if err := reader.Err(); err != nil {
+		releaseRecords(records)
+		return nil, err
+	}
+	return records, nil
+}
+
+func releaseRecords(records []arrow.Record) {
+	for _, record := range records {
+		record.Release()
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// ExecuteBatch runs each statement through ExecuteStatementWithREST with at most concurrency
+// statements in flight. Timings and errors are returned in input order; a failed statement does
+// not stop the others. Statements that never started because ctx was done get ctx's error.
+// For long batches, call CheckTokenLifetime first so an expiring token fails the batch up front.
+func (c *DatabricksRESTClient) ExecuteBatch(ctx context.Context, statements []string, concurrency int) ([]*TimingInfo, []error) {
+	timings := make([]*TimingInfo, len(statements))
+	errs := make([]error, len(statements))
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, stmt := range statements {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			for j := i; j < len(statements); j++ {
+				errs[j] = fmt.Errorf("statement %d not started: %w", j, ctx.Err())
+			}
+			wg.Wait()
+			return timings, errs
+		}
+		wg.Go(func() {
+			defer func() { <-sem }()
+			timings[i], errs[i] = c.ExecuteStatementWithREST(ctx, stmt)
+		})
+	}
+	wg.Wait()
+	return timings, errs
+}
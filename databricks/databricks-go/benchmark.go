@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// Benchmark measures warehouse latency by executing a statement repeatedly and concurrently
+type Benchmark struct {
+	Client StatementExecutor
+	// Warmup is the number of runs executed before measurement starts; their results are discarded
+	Warmup int
+	// MaxInFlight bounds concurrent requests across all workers. Zero means one per worker.
+	MaxInFlight int
+	// DisableResultCache prefixes every run with a unique comment so the warehouse executes it
+	// instead of serving the previous run's result from its result cache
+	DisableResultCache bool
+	// EstimatedDuration, when set, makes Run check first that the client's token outlives it,
+	// failing with ErrTokenExpiresSoon rather than with 401s partway through
+	EstimatedDuration time.Duration
+	// Sink, when set, receives each measured run as it completes and is flushed when Run returns
+	Sink TimingSink
+	// StreamingAfter bounds memory for long soak runs: past this many successful runs, samples
+	// are folded into a streaming percentile estimator instead of being kept. Zero keeps every
+	// sample. TimingStats.RelativeError reports the estimate's accuracy.
+	StreamingAfter int
+	// RelativeError is the streaming estimator's percentile accuracy as a fraction of the value;
+	// zero means 1%
+	RelativeError float64
+}
+
+// concurrencySuggester is implemented by clients that can size concurrency to their warehouse
+type concurrencySuggester interface {
+	SuggestedConcurrency(ctx context.Context) (int, error)
+}
+
+// tokenLifetimeChecker is implemented by clients that can check their credential's expiry
+type tokenLifetimeChecker interface {
+	CheckTokenLifetime(ctx context.Context, d time.Duration) error
+}
+
+// Run launches concurrency workers that each execute statement iterations times and returns the
+// aggregated statistics. A concurrency of 0 uses the client's SuggestedConcurrency for its
+// warehouse. If ctx is canceled mid-run, the statistics for the runs completed so far are
+// returned together with an error.
+func (b *Benchmark) Run(ctx context.Context, statement string, concurrency, iterations int) (TimingStats, error) {
+	if b.Client == nil {
+		return TimingStats{}, errors.New("benchmark: Client is nil")
+	}
+	if concurrency == 0 {
+		suggester, ok := b.Client.(concurrencySuggester)
+		if !ok {
+			return TimingStats{}, errors.New("benchmark: concurrency is 0 but the client cannot suggest one")
+		}
+		suggested, err := suggester.SuggestedConcurrency(ctx)
+		if err != nil {
+			return TimingStats{}, fmt.Errorf("benchmark: failed to size concurrency: %w", err)
+		}
+		concurrency = suggested
+	}
+	if concurrency < 1 || iterations < 0 {
+		return TimingStats{}, fmt.Errorf("benchmark: invalid concurrency %d or iterations %d", concurrency, iterations)
+	}
+
+	if checker, ok := b.Client.(tokenLifetimeChecker); ok && b.EstimatedDuration > 0 {
+		if err := checker.CheckTokenLifetime(ctx, b.EstimatedDuration); err != nil {
+			return TimingStats{}, fmt.Errorf("benchmark pre-flight: %w", err)
+		}
+	}
+
+	for i := 0; i < b.Warmup; i++ {
+		if err := ctx.Err(); err != nil {
+			return TimingStats{}, fmt.Errorf("benchmark canceled during warmup: %w", err)
+		}
+		b.runOnce(ctx, statement)
+	}
+
+	maxInFlight := b.MaxInFlight
+	if maxInFlight <= 0 {
+		maxInFlight = concurrency
+	}
+	sem := make(chan struct{}, maxInFlight)
+
+	var (
+		mu      sync.Mutex
+		timings = timingAccumulator{streamAfter: b.StreamingAfter, relativeError: b.RelativeError}
+		sinkErr error
+		wg      sync.WaitGroup
+	)
+	for w := 0; w < concurrency; w++ {
+		wg.Go(func() {
+			for i := 0; i < iterations; i++ {
+				select {
+				case sem <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+				timing := b.runOnce(ctx, statement)
+				<-sem
+
+				// A run cut short by cancellation says nothing about warehouse latency
+				if ctx.Err() != nil {
+					return
+				}
+				mu.Lock()
+				timings.add(timing)
+				if b.Sink != nil && sinkErr == nil {
+					sinkErr = b.Sink.Record(timing)
+				}
+				mu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+
+	stats := timings.stats()
+	if b.Sink != nil {
+		if err := errors.Join(sinkErr, b.Sink.Flush()); err != nil {
+			return stats, fmt.Errorf("benchmark sink: %w", err)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return stats, fmt.Errorf("benchmark canceled after %d of %d runs: %w", timings.count(), concurrency*iterations, err)
+	}
+	return stats, nil
+}
+
+// runOnce executes the statement, folding any error into the returned TimingInfo
+func (b *Benchmark) runOnce(ctx context.Context, statement string) TimingInfo {
+	if b.DisableResultCache {
+		// The result cache is keyed on the exact statement text
+		statement = fmt.Sprintf("/* benchmark run %016x */ %s", rand.Uint64(), statement)
+	}
+	timing, err := b.Client.ExecuteStatementWithREST(ctx, statement)
+	if timing == nil {
+		timing = &TimingInfo{Method: MethodRESTAPI, StatementText: statement}
+	}
+	if err != nil && timing.ErrorMessage == "" {
+		timing.ErrorMessage = err.Error()
+	}
+	// Leaving a queued run behind would only deepen the queue for the runs after it
+	var capacityErr *CapacityError
+	if errors.As(err, &capacityErr) {
+		cancelCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cancelTimeout)
+		defer cancel()
+		if err := b.Client.CancelStatement(cancelCtx, capacityErr.StatementID); err != nil {
+			b.logger().WarnContext(ctx, "failed to cancel queued benchmark run; it may still be running",
+				slog.String("statement_id", capacityErr.StatementID),
+				slog.String("error", err.Error()))
+		}
+	}
+	return *timing
+}
+
+// logger returns the client's logger, or the default logger for other StatementExecutors
+func (b *Benchmark) logger() *slog.Logger {
+	if c, ok := b.Client.(*DatabricksRESTClient); ok {
+		return c.logger
+	}
+	return slog.Default()
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"databricks-go-timing-test/testserver"
+)
+
+// queuedExecutor reports every statement as still queued and fails to cancel it
+type queuedExecutor struct {
+	mu                sync.Mutex
+	runs              int
+	cancelHadDeadline bool
+}
+
+func (e *queuedExecutor) ExecuteStatementWithREST(ctx context.Context, statement string) (*TimingInfo, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.runs++
+	return &TimingInfo{Method: MethodRESTAPI, QueryID: "01f0-queued", State: StatePending},
+		&CapacityError{StatementID: "01f0-queued", State: StatePending, Queued: time.Second}
+}
+
+func (e *queuedExecutor) GetStatementTiming(ctx context.Context, statementID string) (*TimingInfo, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (e *queuedExecutor) CancelStatement(ctx context.Context, statementID string) error {
+	deadline, ok := ctx.Deadline()
+	e.mu.Lock()
+	e.cancelHadDeadline = ok && time.Until(deadline) <= cancelTimeout
+	e.mu.Unlock()
+	return errors.New("503 TEMPORARILY_UNAVAILABLE")
+}
+
+func TestBenchmarkCancelsQueuedRuns(t *testing.T) {
+	var logs bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+
+	executor := &queuedExecutor{}
+	b := &Benchmark{Client: executor}
+	stats, err := b.Run(context.Background(), "SELECT 1", 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.AtCapacity != 1 || stats.N != 0 {
+		t.Errorf("stats = %+v, want the one run counted as at capacity", stats)
+	}
+	if !executor.cancelHadDeadline {
+		t.Error("cancel of the queued run had no deadline")
+	}
+	if out := logs.String(); !strings.Contains(out, "failed to cancel queued benchmark run") || !strings.Contains(out, "01f0-queued") {
+		t.Errorf("failed cancel was not logged; logs:\n%s", out)
+	}
+}
+
+func TestBenchmarkRun(t *testing.T) {
+	client, handler := newTestClient(t)
+	handler.AddStatement(testserver.Statement{Text: "SELECT 1", Rows: testserver.IntRows(1)})
+
+	b := &Benchmark{Client: client, Warmup: 1}
+	stats, err := b.Run(context.Background(), "SELECT 1", 4, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.N != 20 || stats.Failures != 0 {
+		t.Errorf("stats = %+v, want 20 successful runs", stats)
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"container/list"
+	"sync"
+)
+
+// lruCache is a fixed-size, concurrency-safe least-recently-used cache
+type lruCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type lruEntry struct {
+	key   string
+	value any
+}
+
+func newLRUCache(size int) *lruCache {
+	return &lruCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element, size),
+	}
+}
+
+func (l *lruCache) get(key string) (any, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	elem, ok := l.entries[key]
+	if !ok {
+		return nil, false
+	}
+	l.order.MoveToFront(elem)
+	return elem.Value.(*lruEntry).value, true
+}
+
+func (l *lruCache) add(key string, value any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if elem, ok := l.entries[key]; ok {
+		elem.Value.(*lruEntry).value = value
+		l.order.MoveToFront(elem)
+		return
+	}
+	l.entries[key] = l.order.PushFront(&lruEntry{key: key, value: value})
+	if l.order.Len() > l.size {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.entries, oldest.Value.(*lruEntry).key)
+	}
+}
+
+func (l *lruCache) clear() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.order.Init()
+	clear(l.entries)
+}
+
+// Cache key prefixes keep the two history sources apart for the same statement ID
+const (
+	cacheKeyHistoryAPI   = "history-api/"
+	cacheKeyHistoryTable = "history-table/"
+)
+
+// isFinishedHistoryStatus reports whether a history status can no longer change and may be cached
+func isFinishedHistoryStatus(status string) bool {
+	switch status {
+	case "FINISHED", "FAILED", "CANCELED":
+		return true
+	}
+	return false
+}
+
+// cachedHistory returns a copy of a cached history value, if caching is enabled and it is present
+func cachedHistory[T any](c *DatabricksRESTClient, key string) (*T, bool) {
+	if c.cache == nil {
+		return nil, false
+	}
+	v, ok := c.cache.get(key)
+	if !ok {
+		return nil, false
+	}
+	copied := v.(T)
+	return &copied, true
+}
+
+// cacheHistory stores a history value when caching is enabled and its status is final
+func cacheHistory[T any](c *DatabricksRESTClient, key, status string, value *T) {
+	if c.cache != nil && isFinishedHistoryStatus(status) {
+		c.cache.add(key, *value)
+	}
+}
+
+// ClearCache discards every cached history response. It is a no-op when caching is disabled.
+func (c *DatabricksRESTClient) ClearCache() {
+	if c.cache != nil {
+		c.cache.clear()
+	}
+}
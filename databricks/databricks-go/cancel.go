@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// CancelStatement requests cancellation of a running statement. A non-200 response is
+// returned as a wrapped *APIError.
+func (c *DatabricksRESTClient) CancelStatement(ctx context.Context, statementID string) error {
+	if err := c.doJSON(ctx, "POST", "/api/2.0/sql/statements/"+statementID+"/cancel", nil, nil); err != nil {
+		return fmt.Errorf("failed to cancel statement %s: %w", statementID, err)
+	}
+	c.logger.DebugContext(ctx, "statement canceled", slog.String("statement_id", statementID))
+	return nil
+}
+
+// ExecuteCancellable runs a query through the SQL driver like ExecuteWithIDs. If ctx is canceled
+// or times out before the query finishes, the driver only abandons its connection, so the
+// captured query ID is also canceled over REST to make sure the warehouse stops working on it.
+// The returned bool reports whether that server-side cancel was accepted; a failed cancel is
+// joined to the driver error.
+func (c *DatabricksRESTClient) ExecuteCancellable(ctx context.Context, db *sql.DB, query string) (ExecResult, bool, error) {
+	result, err := ExecuteWithIDs(ctx, db, query)
+	if err == nil || ctx.Err() == nil {
+		return result, false, err
+	}
+	if result.QueryID == "" {
+		c.logger.WarnContext(ctx, "query canceled before the driver reported its ID; server-side cancel skipped")
+		return result, false, err
+	}
+
+	cancelCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cancelTimeout)
+	defer cancel()
+	if cancelErr := c.CancelStatement(cancelCtx, result.QueryID); cancelErr != nil {
+		return result, false, errors.Join(err, cancelErr)
+	}
+	return result, true, err
+}
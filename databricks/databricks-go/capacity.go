@@ -0,0 +1,45 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrWarehouseAtCapacity is wrapped by *CapacityError when a statement was still queued when
+// its wait_timeout expired, meaning the warehouse had no free slot rather than the query being slow
+var ErrWarehouseAtCapacity = errors.New("warehouse at capacity")
+
+// StateQueued is reported by some endpoints for statements waiting for a free slot; the Statement
+// Execution API reports them as PENDING
+const StateQueued = "QUEUED"
+
+// CapacityError reports a statement that spent its whole wait_timeout queued. The statement is
+// left queued on the warehouse; wait for it with WaitForStatement or cancel it with
+// CancelStatement. The API does not expose the warehouse's queue depth.
+type CapacityError struct {
+	StatementID string
+	State       string
+	// Queued is how long the client observed the statement waiting
+	Queued time.Duration
+}
+
+func (e *CapacityError) Error() string {
+	return fmt.Sprintf("statement %s still %s after %s: %v", e.StatementID, e.State, e.Queued.Round(time.Millisecond), ErrWarehouseAtCapacity)
+}
+
+func (e *CapacityError) Unwrap() error {
+	return ErrWarehouseAtCapacity
+}
+
+func isQueuedState(state string) bool {
+	return state == StatePending || state == StateQueued
+}
+
+// capacityError returns a *CapacityError when a synchronous submit came back still queued, or nil
+func capacityError(resp *StatementExecutionResponse, waitTimeout string, queued time.Duration) error {
+	if waitTimeout == "0s" || !isQueuedState(resp.Status.State) {
+		return nil
+	}
+	return &CapacityError{StatementID: resp.StatementID, State: resp.Status.State, Queued: queued}
+}
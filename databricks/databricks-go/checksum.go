@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"hash"
+)
+
+// ResultHasher computes a SHA-256 checksum of a result's schema and rows as they stream in.
+// Rows are hashed one at a time from their raw JSON_ARRAY cells, so the checksum is the same
+// however the rows were split into chunks.
+type ResultHasher struct {
+	orderIndependent bool
+	// ordered hashes the schema and then every row in sequence
+	ordered hash.Hash
+	// rowSum is the 256-bit sum of the per-row hashes, which doesn't depend on row order but
+	// still counts duplicate rows, unlike XOR
+	rowSum [sha256.Size]byte
+	rows   uint64
+	schema [sha256.Size]byte
+}
+
+// NewResultHasher starts a checksum over results with the given schema. With orderIndependent
+// the same rows in any order produce the same checksum, for statements without an ORDER BY.
+func NewResultHasher(schema Schema, orderIndependent bool) *ResultHasher {
+	h := &ResultHasher{orderIndependent: orderIndependent, ordered: sha256.New()}
+
+	schemaHash := sha256.New()
+	for _, col := range schema.Columns {
+		writeCell(schemaHash, &col.Name)
+		typ := columnType(col)
+		writeCell(schemaHash, &typ)
+	}
+	copy(h.schema[:], schemaHash.Sum(nil))
+	h.ordered.Write(h.schema[:])
+	return h
+}
+
+// AddRows adds rows, in result order, to the checksum
+func (h *ResultHasher) AddRows(rows [][]*string) {
+	for _, row := range rows {
+		h.rows++
+		if !h.orderIndependent {
+			writeRow(h.ordered, row)
+			continue
+		}
+		rowHash := sha256.New()
+		writeRow(rowHash, row)
+		addInto(&h.rowSum, rowHash.Sum(nil))
+	}
+}
+
+// Sum returns the hex-encoded checksum of the schema and every row added so far
+func (h *ResultHasher) Sum() string {
+	if !h.orderIndependent {
+		return hex.EncodeToString(h.ordered.Sum(nil))
+	}
+	final := sha256.New()
+	final.Write(h.schema[:])
+	final.Write(h.rowSum[:])
+	binary.Write(final, binary.BigEndian, h.rows)
+	return hex.EncodeToString(final.Sum(nil))
+}
+
+// Checksum returns the checksum of a fully materialized result, such as one from
+// ExecuteStatement. For results too large to hold in memory use ChecksumStatement.
+func (resp *StatementExecutionResponse) Checksum(orderIndependent bool) (string, error) {
+	if len(resp.Result.ExternalLinks) > 0 || int64(len(resp.Result.DataArray)) != resp.Manifest.TotalRowCount {
+		return "", errors.New("checksum: result is not fully materialized")
+	}
+	h := NewResultHasher(resp.Manifest.Schema, orderIndependent)
+	h.AddRows(resp.Result.DataArray)
+	return h.Sum(), nil
+}
+
+// ChecksumStatement runs a statement and checksums its result chunk by chunk, holding only one
+// chunk in memory at a time. It matches Checksum of the same result materialized.
+func (c *DatabricksRESTClient) ChecksumStatement(ctx context.Context, statement string, orderIndependent bool) (string, *TimingInfo, error) {
+	timing, resp, err := c.executeStatement(ctx, c.newStatementRequest(statement))
+	if err != nil {
+		return "", timing, err
+	}
+
+	ctx, transfer := withTransferStats(ctx)
+	defer transfer.applyTo(timing)
+
+	h := NewResultHasher(resp.Manifest.Schema, orderIndependent)
+	err = c.walkChunks(ctx, resp.StatementID, resp.Manifest, &resp.Result, func(chunk *ResultData) error {
+		if len(chunk.ExternalLinks) == 0 {
+			h.AddRows(chunk.DataArray)
+			return nil
+		}
+		for _, link := range chunk.ExternalLinks {
+			data, err := c.downloadExternalLink(ctx, link)
+			if err != nil {
+				return newChunkFetchError(resp.StatementID, chunk.index(), err)
+			}
+			h.AddRows(data)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", timing, err
+	}
+	return h.Sum(), timing, nil
+}
+
+// writeRow writes a row's cells with their column count, so rows can't run into each other
+func writeRow(w hash.Hash, row []*string) {
+	binary.Write(w, binary.BigEndian, uint32(len(row)))
+	for _, cell := range row {
+		writeCell(w, cell)
+	}
+}
+
+// writeCell writes a length-prefixed cell, keeping NULL distinct from the empty string
+func writeCell(w hash.Hash, cell *string) {
+	if cell == nil {
+		w.Write([]byte{0})
+		return
+	}
+	w.Write([]byte{1})
+	binary.Write(w, binary.BigEndian, uint64(len(*cell)))
+	w.Write([]byte(*cell))
+}
+
+// addInto adds b to the big-endian 256-bit integer sum, discarding the final carry
+func addInto(sum *[sha256.Size]byte, b []byte) {
+	var carry uint16
+	for i := len(sum) - 1; i >= 0; i-- {
+		total := uint16(sum[i]) + uint16(b[i]) + carry
+		sum[i] = byte(total)
+		carry = total >> 8
+	}
+}
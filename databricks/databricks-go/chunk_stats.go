@@ -0,0 +1,34 @@
+package main
+
+// chunkSkewFactor is how many times the mean row count the largest chunk must hold to count as skewed
+const chunkSkewFactor = 2.0
+
+// ChunkRowCounts returns the row count of each chunk, in chunk order, from the manifest
+func (resp *StatementExecutionResponse) ChunkRowCounts() []int {
+	counts := make([]int, len(resp.Manifest.Chunks))
+	for i, chunk := range resp.Manifest.Chunks {
+		counts[i] = int(chunk.RowCount)
+	}
+	return counts
+}
+
+// ChunkSkew returns the largest chunk's row count divided by the mean, 1 for perfectly even
+// chunks. It returns 0 when there are no rows.
+func ChunkSkew(counts []int) float64 {
+	var total, largest int
+	for _, n := range counts {
+		total += n
+		largest = max(largest, n)
+	}
+	if total == 0 {
+		return 0
+	}
+	mean := float64(total) / float64(len(counts))
+	return float64(largest) / mean
+}
+
+// IsChunkSkewed reports whether the largest chunk holds more than twice the mean row count, in
+// which case its download tends to dominate the fetch time of an EXTERNAL_LINKS result
+func IsChunkSkewed(counts []int) bool {
+	return ChunkSkew(counts) > chunkSkewFactor
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// ChunkFetchError reports a chunk that could not be fetched or decoded after retries. Every
+// chunk up to and including LastGood was read, so a caller can keep those rows and resume with
+// FetchAllChunks from LastGood+1.
+type ChunkFetchError struct {
+	StatementID string
+	// Chunk is the index of the chunk that failed
+	Chunk int
+	// LastGood is the index of the last chunk read successfully, or Chunk-1 when none were
+	LastGood int
+	Err      error
+}
+
+func (e *ChunkFetchError) Error() string {
+	return fmt.Sprintf("statement %s: chunk %d failed: %v", e.StatementID, e.Chunk, e.Err)
+}
+
+func (e *ChunkFetchError) Unwrap() error {
+	return e.Err
+}
+
+func newChunkFetchError(statementID string, chunk int, err error) *ChunkFetchError {
+	return &ChunkFetchError{StatementID: statementID, Chunk: chunk, LastGood: chunk - 1, Err: err}
+}
+
+// FetchAllChunks retrieves and decodes every chunk of a statement's result from startChunk
+// onward, following next_chunk_internal_link until all chunks are read. Chunk GETs are retried
+// per the client's retry policy. If a chunk still fails, the rows of the chunks before it are
+// returned together with a *ChunkFetchError whose LastGood tells where to resume.
+func (c *DatabricksRESTClient) FetchAllChunks(ctx context.Context, statementID string, manifest Manifest, startChunk int) ([][]any, error) {
+	if manifest.TotalChunkCount == 0 {
+		return [][]any{}, nil
+	}
+	if startChunk < 0 || startChunk >= manifest.TotalChunkCount {
+		return nil, fmt.Errorf("statement %s: start chunk %d out of range [0, %d)", statementID, startChunk, manifest.TotalChunkCount)
+	}
+
+	first, err := c.fetchChunk(ctx, statementID, chunkPath(statementID, startChunk), startChunk)
+	if err != nil {
+		return [][]any{}, newChunkFetchError(statementID, startChunk, err)
+	}
+	return c.followChunks(ctx, statementID, manifest, first)
+}
+
+// followChunks decodes the given chunk and every chunk after it. On failure it returns the rows
+// decoded so far together with a *ChunkFetchError.
+func (c *DatabricksRESTClient) followChunks(ctx context.Context, statementID string, manifest Manifest, chunk *ResultData) ([][]any, error) {
+	rows := make([][]any, 0, int(manifest.TotalRowCount))
+	err := c.walkChunks(ctx, statementID, manifest, chunk, func(chunk *ResultData) error {
+		decoded, err := c.decodeChunk(ctx, manifest.Schema, chunk)
+		if err != nil {
+			return newChunkFetchError(statementID, chunk.index(), fmt.Errorf("failed to decode: %w", err))
+		}
+		rows = append(rows, decoded...)
+		return nil
+	})
+	return rows, err
+}
+
+// materializeResult fetches every remaining chunk and assembles all rows into resp.Result.DataArray
+func (c *DatabricksRESTClient) materializeResult(ctx context.Context, resp *StatementExecutionResponse) error {
+	data := make([][]*string, 0, int(resp.Manifest.TotalRowCount))
+	err := c.walkChunks(ctx, resp.StatementID, resp.Manifest, &resp.Result, func(chunk *ResultData) error {
+		if len(chunk.ExternalLinks) == 0 {
+			data = append(data, chunk.DataArray...)
+			return nil
+		}
+		for _, link := range chunk.ExternalLinks {
+			linkData, err := c.downloadExternalLink(ctx, link)
+			if err != nil {
+				return newChunkFetchError(resp.StatementID, chunk.index(), err)
+			}
+			data = append(data, linkData...)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	resp.Result = ResultData{
+		RowCount:  int64(len(data)),
+		DataArray: data,
+	}
+	return nil
+}
+
+// walkChunks calls fn for the given chunk and then fetches and visits each following chunk.
+// A missing link before the last chunk is an error rather than a silently truncated result.
+// Fetch failures are returned as *ChunkFetchError; errors from fn are returned as is.
+func (c *DatabricksRESTClient) walkChunks(ctx context.Context, statementID string, manifest Manifest, chunk *ResultData, fn func(*ResultData) error) error {
+	for index := chunk.index(); ; index++ {
+		if err := fn(chunk); err != nil {
+			return err
+		}
+
+		if index >= manifest.TotalChunkCount-1 {
+			return nil
+		}
+		nextLink := chunk.nextLink()
+		if nextLink == "" {
+			return newChunkFetchError(statementID, index+1, fmt.Errorf("link to chunk %d of %d is missing", index+1, manifest.TotalChunkCount))
+		}
+
+		var err error
+		chunk, err = c.fetchChunk(ctx, statementID, nextLink, index+1)
+		if err != nil {
+			return newChunkFetchError(statementID, index+1, err)
+		}
+	}
+}
+
+// fetchChunk GETs a single result chunk by its API path
+func (c *DatabricksRESTClient) fetchChunk(ctx context.Context, statementID, path string, index int) (*ResultData, error) {
+	startTime := time.Now()
+	var raw json.RawMessage
+	if err := c.doJSON(ctx, "GET", path, nil, &raw); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("fetch of chunk %d of statement %s canceled while in flight: %w", index, statementID, ctx.Err())
+		}
+		return nil, fmt.Errorf("failed to fetch chunk %d of statement %s: %w", index, statementID, err)
+	}
+	var chunk ResultData
+	if err := json.Unmarshal(raw, &chunk); err != nil {
+		return nil, fmt.Errorf("failed to parse chunk %d of statement %s: %w", index, statementID, err)
+	}
+	recordTransfer(ctx, len(raw), len(chunk.ExternalLinks) == 0, time.Since(startTime))
+	if chunk.index() != index {
+		return nil, fmt.Errorf("statement %s: expected chunk %d but received chunk %d", statementID, index, chunk.index())
+	}
+	c.logger.DebugContext(ctx, "chunk fetched",
+		slog.String("statement_id", statementID),
+		slog.Int("chunk_index", index))
+	return &chunk, nil
+}
+
+// decodeChunk decodes a chunk's inline data_array, or downloads its external links when present
+func (c *DatabricksRESTClient) decodeChunk(ctx context.Context, schema Schema, chunk *ResultData) ([][]any, error) {
+	if len(chunk.ExternalLinks) > 0 {
+		return c.downloadExternalLinks(ctx, schema, chunk.ExternalLinks)
+	}
+	return decodeRows(schema, chunk.DataArray, c.typeConverter())
+}
+
+// index returns the chunk index, which EXTERNAL_LINKS results only report per link
+func (r *ResultData) index() int {
+	if len(r.ExternalLinks) > 0 {
+		return r.ExternalLinks[0].ChunkIndex
+	}
+	return r.ChunkIndex
+}
+
+// nextLink returns the API path of the following chunk, or "" if there is none
+func (r *ResultData) nextLink() string {
+	if r.NextChunkInternalLink != "" {
+		return r.NextChunkInternalLink
+	}
+	if len(r.ExternalLinks) > 0 {
+		return r.ExternalLinks[len(r.ExternalLinks)-1].NextChunkInternalLink
+	}
+	return ""
+}
+
+func chunkPath(statementID string, index int) string {
+	return fmt.Sprintf("/api/2.0/sql/statements/%s/result/chunks/%d", statementID, index)
+}
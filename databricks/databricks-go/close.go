@@ -0,0 +1,26 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+// CloseStatement deletes a statement's server-side result so it is not retained for the result
+// TTL. A 404 means the statement is already closed and is treated as success, so the call is
+// idempotent. Other failures are returned as a wrapped *APIError.
+func (c *DatabricksRESTClient) CloseStatement(ctx context.Context, statementID string) error {
+	err := c.doJSON(ctx, "DELETE", "/api/2.0/sql/statements/"+statementID, nil, nil)
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to close statement %s: %w", statementID, err)
+	}
+	c.logger.DebugContext(ctx, "statement closed", slog.String("statement_id", statementID))
+	return nil
+}
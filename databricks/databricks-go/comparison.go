@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+)
+
+// TimingComparison pairs driver-side and REST-side timing for the same statement,
+// optionally with the server-side view from system.query.history
+type TimingComparison struct {
+	GoDriverTiming   TimingInfo            `json:"go_driver_timing"`
+	RESTAPITiming    TimingInfo            `json:"rest_api_timing"`
+	QueryHistoryInfo *QueryHistoryResponse `json:"query_history_info,omitempty"`
+}
+
+// MarshalJSON adds the build that produced the comparison, so archived reports are
+// self-describing
+func (tc TimingComparison) MarshalJSON() ([]byte, error) {
+	type plain TimingComparison
+	return json.Marshal(struct {
+		plain
+		Build BuildInfo `json:"build"`
+	}{plain(tc), CurrentBuild()})
+}
+
+var timingCSVHeader = []string{
+	"method", "query_id", "statement_text", "duration_ms", "row_count", "column_count", "error_message",
+	"total_duration_ms", "execution_duration_ms", "compilation_duration_ms",
+	"bytes_downloaded", "chunk_download_ms", "num_chunks_fetched",
+}
+
+// WriteCSV writes a header and one row per method (GO_DRIVER, REST_API), plus a QUERY_HISTORY
+// row when history is present. Fields containing commas, quotes or newlines are quoted per RFC 4180.
+func (tc TimingComparison) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	records := [][]string{
+		timingCSVHeader,
+		timingCSVRecord(tc.GoDriverTiming),
+		timingCSVRecord(tc.RESTAPITiming),
+	}
+	if h := tc.QueryHistoryInfo; h != nil {
+		records = append(records, []string{
+			MethodQueryHistory, h.StatementID, h.StatementText, "", strconv.FormatInt(h.ProducedRows, 10), "", "",
+			strconv.FormatInt(h.TotalDurationMs, 10),
+			strconv.FormatInt(h.ExecutionDurationMs, 10),
+			strconv.FormatInt(h.CompilationDurationMs, 10),
+			"", "", "",
+		})
+	}
+
+	if err := cw.WriteAll(records); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+func timingCSVRecord(t TimingInfo) []string {
+	return []string{
+		t.Method, t.QueryID, t.StatementText,
+		strconv.FormatInt(t.DurationMs, 10),
+		strconv.FormatInt(t.RowCount, 10),
+		strconv.Itoa(t.ColumnCount),
+		t.ErrorMessage,
+		"", "", "",
+		strconv.FormatInt(t.BytesDownloaded, 10),
+		strconv.FormatInt(t.ChunkDownloadMs, 10),
+		strconv.Itoa(t.NumChunksFetched),
+	}
+}
+
+// TimingSkew separates a client's wall time for a statement into network/client overhead and
+// the server's own phases
+type TimingSkew struct {
+	// NetworkOverheadMs is the client wall time minus the server's total duration. It can be
+	// negative when client and server clocks measure slightly different spans.
+	NetworkOverheadMs int64 `json:"network_overhead_ms"`
+	CompilationMs     int64 `json:"compilation_ms"`
+	ExecutionMs       int64 `json:"execution_ms"`
+	// SuspiciousSkew is set when overhead exceeds server execution time, i.e. the client-side
+	// measurement is dominated by something other than the query itself
+	SuspiciousSkew bool `json:"suspicious_skew"`
+}
+
+// CompareTimings breaks a driver-side measurement down against the statement's
+// system.query.history entry
+func CompareTimings(driver TimingInfo, history QueryHistoryResponse) TimingSkew {
+	overhead := driver.DurationMs - history.TotalDurationMs
+	return TimingSkew{
+		NetworkOverheadMs: overhead,
+		CompilationMs:     history.CompilationDurationMs,
+		ExecutionMs:       history.ExecutionDurationMs,
+		SuspiciousSkew:    overhead > history.ExecutionDurationMs,
+	}
+}
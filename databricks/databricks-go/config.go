@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+const defaultPort = 443
+
+// Config holds the connection settings shared by the SQL driver and REST client
+type Config struct {
+	Token       string
+	Hostname    string
+	WarehouseID string
+	// WarehouseName may be given instead of WarehouseID; it survives warehouse recreation and is
+	// resolved to an ID by Resolve, or by OpenDB on the first connection. WarehouseID wins when
+	// both are set.
+	WarehouseName string
+	// Port defaults to 443 when zero
+	Port int
+	// Catalog and Schema, set together, are the default namespace for unqualified table names,
+	// both for driver sessions and for REST statements
+	Catalog string
+	Schema  string
+}
+
+// DSN builds a databricks-sql-go DSN of the form token:<token>@<host>:<port>/sql/1.0/endpoints/<id>.
+// The token is URL-escaped so tokens containing reserved characters survive parsing.
+func (c Config) DSN() (string, error) {
+	if err := c.validate(); err != nil {
+		return "", err
+	}
+	if c.WarehouseID == "" {
+		return "", fmt.Errorf("config: warehouse name %q must be resolved to an ID first; use Config.Resolve", c.WarehouseName)
+	}
+
+	userinfo := url.UserPassword("token", c.Token).String()
+	dsn := fmt.Sprintf("%s@%s:%d/sql/1.0/endpoints/%s", userinfo, c.Hostname, c.port(), c.WarehouseID)
+	if c.Catalog != "" {
+		dsn += "?" + url.Values{"catalog": {c.Catalog}, "schema": {c.Schema}}.Encode()
+	}
+	return dsn, nil
+}
+
+func (c Config) port() int {
+	if c.Port == 0 {
+		return defaultPort
+	}
+	return c.Port
+}
+
+func (c Config) validate() error {
+	if c.Token == "" {
+		return errors.New("config: token is empty")
+	}
+	if c.Hostname == "" {
+		return errors.New("config: hostname is empty")
+	}
+	if strings.Contains(c.Hostname, "://") {
+		return fmt.Errorf("config: hostname %q must not include a scheme", c.Hostname)
+	}
+	if strings.ContainsAny(c.Hostname, "/:@ \t") {
+		return fmt.Errorf("config: hostname %q is not a bare host name", c.Hostname)
+	}
+	if c.WarehouseID == "" && c.WarehouseName == "" {
+		return errors.New("config: warehouse ID and name are both empty")
+	}
+	if c.Port < 0 || c.Port > 65535 {
+		return fmt.Errorf("config: invalid port %d", c.Port)
+	}
+	if err := validateNamespace(c.Catalog, c.Schema); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	return nil
+}
+
+// validateNamespace requires catalog and schema to be set together or not at all, since a schema
+// alone would resolve against whatever catalog the warehouse defaults to
+func validateNamespace(catalog, schema string) error {
+	if (catalog == "") != (schema == "") {
+		return fmt.Errorf("catalog %q and schema %q must be set together", catalog, schema)
+	}
+	return nil
+}
+
+// ClientOptions returns the REST client options that carry the config's settings beyond host,
+// token and warehouse, currently its default namespace
+func (c Config) ClientOptions() []ClientOption {
+	if c.Catalog == "" {
+		return nil
+	}
+	return []ClientOption{WithNamespace(c.Catalog, c.Schema)}
+}
+
+// Resolve returns a copy of the config with WarehouseID looked up from WarehouseName. A config
+// that already has a WarehouseID is returned as is.
+func (c Config) Resolve(ctx context.Context) (Config, error) {
+	if err := c.validate(); err != nil {
+		return Config{}, err
+	}
+	if c.WarehouseID != "" {
+		return c, nil
+	}
+	id, err := NewDatabricksRESTClient(c.host(), c.Token, "").ResolveWarehouseID(ctx, c.WarehouseName)
+	if err != nil {
+		return Config{}, err
+	}
+	c.WarehouseID = id
+	return c, nil
+}
+
+// host returns the hostname with a non-default port, as the REST client expects it
+func (c Config) host() string {
+	if c.port() == defaultPort {
+		return c.Hostname
+	}
+	return fmt.Sprintf("%s:%d", c.Hostname, c.port())
+}
+
+// ConfigFromEnv reads DATABRICKS_TOKEN, DATABRICKS_HOST and DATABRICKS_WAREHOUSE_ID, or
+// DATABRICKS_WAREHOUSE_NAME in place of the ID, and the optional DATABRICKS_CATALOG and
+// DATABRICKS_SCHEMA. DATABRICKS_HOST may include an https:// scheme, which is stripped.
+func ConfigFromEnv() (Config, error) {
+	cfg := Config{
+		Token:         os.Getenv("DATABRICKS_TOKEN"),
+		Hostname:      strings.TrimSuffix(strings.TrimPrefix(os.Getenv("DATABRICKS_HOST"), "https://"), "/"),
+		WarehouseID:   os.Getenv("DATABRICKS_WAREHOUSE_ID"),
+		WarehouseName: os.Getenv("DATABRICKS_WAREHOUSE_NAME"),
+		Catalog:       os.Getenv("DATABRICKS_CATALOG"),
+		Schema:        os.Getenv("DATABRICKS_SCHEMA"),
+	}
+
+	var missing []string
+	if cfg.Token == "" {
+		missing = append(missing, "DATABRICKS_TOKEN")
+	}
+	if cfg.Hostname == "" {
+		missing = append(missing, "DATABRICKS_HOST")
+	}
+	if cfg.WarehouseID == "" && cfg.WarehouseName == "" {
+		missing = append(missing, "DATABRICKS_WAREHOUSE_ID")
+	}
+	if len(missing) > 0 {
+		return Config{}, fmt.Errorf("missing environment variables: %s", strings.Join(missing, ", "))
+	}
+	if err := validateNamespace(cfg.Catalog, cfg.Schema); err != nil {
+		return Config{}, fmt.Errorf("DATABRICKS_CATALOG and DATABRICKS_SCHEMA: %w", err)
+	}
+	return cfg, nil
+}
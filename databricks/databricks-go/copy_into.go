@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// copyFileFormats are the FILEFORMAT values COPY INTO accepts
+var copyFileFormats = []string{"CSV", "JSON", "AVRO", "ORC", "PARQUET", "TEXT", "BINARYFILE"}
+
+// CopyOptions holds the option maps of a COPY INTO statement, e.g. FormatOptions
+// {"header": "true"} for CSV or CopyOptions {"mergeSchema": "true"}
+type CopyOptions struct {
+	FormatOptions map[string]string
+	CopyOptions   map[string]string
+}
+
+// CopyResult is the load summary reported by COPY INTO
+type CopyResult struct {
+	NumAffectedRows        int64
+	NumInsertedRows        int64
+	NumSkippedCorruptFiles int64
+}
+
+// CopyInto bulk-loads the files at sourcePath, e.g. a volume directory filled by UploadToVolume,
+// into a catalog.schema.table and returns the load summary. COPY INTO skips files it has already
+// loaded, so rerunning it after a partial failure is safe.
+func (c *DatabricksRESTClient) CopyInto(ctx context.Context, targetTable, sourcePath, fileFormat string, opts CopyOptions) (CopyResult, error) {
+	if err := validateTableName(targetTable); err != nil {
+		return CopyResult{}, err
+	}
+	if sourcePath == "" {
+		return CopyResult{}, fmt.Errorf("copy into %s: source path is empty", targetTable)
+	}
+	format := strings.ToUpper(fileFormat)
+	if !slices.Contains(copyFileFormats, format) {
+		return CopyResult{}, fmt.Errorf("copy into %s: unsupported file format %q", targetTable, fileFormat)
+	}
+
+	stmt := fmt.Sprintf("COPY INTO %s FROM %s FILEFORMAT = %s", targetTable, quoteString(sourcePath), format)
+	if len(opts.FormatOptions) > 0 {
+		stmt += " FORMAT_OPTIONS (" + copyOptionList(opts.FormatOptions) + ")"
+	}
+	if len(opts.CopyOptions) > 0 {
+		stmt += " COPY_OPTIONS (" + copyOptionList(opts.CopyOptions) + ")"
+	}
+
+	resultSchema, rows, err := c.query(ctx, stmt)
+	if err != nil {
+		return CopyResult{}, fmt.Errorf("failed to copy into %s: %w", targetTable, err)
+	}
+	if len(rows) == 0 {
+		return CopyResult{}, fmt.Errorf("copy into %s returned no summary", targetTable)
+	}
+
+	r := namedRow{index: columnIndexes(resultSchema), row: rows[0]}
+	var result CopyResult
+	if result.NumAffectedRows, err = r.Int64("num_affected_rows"); err != nil {
+		return CopyResult{}, err
+	}
+	if result.NumInsertedRows, err = r.Int64("num_inserted_rows"); err != nil {
+		return CopyResult{}, err
+	}
+	if result.NumSkippedCorruptFiles, err = r.Int64("num_skipped_corrupt_files"); err != nil {
+		return CopyResult{}, err
+	}
+	return result, nil
+}
+
+// copyOptionList renders options as 'key' = 'value' pairs in key order
+func copyOptionList(options map[string]string) string {
+	keys := make([]string, 0, len(options))
+	for key := range options {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = quoteString(key) + " = " + quoteString(options[key])
+	}
+	return strings.Join(pairs, ", ")
+}
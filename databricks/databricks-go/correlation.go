@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/databricks/databricks-sql-go/driverctx"
+)
+
+// correlationIDHeader carries the correlation ID on every REST request
+const correlationIDHeader = "X-Databricks-Correlation-Id"
+
+type correlationIDKey struct{}
+
+// WithCorrelationID tags ctx with a correlation ID for both the REST client and the SQL driver.
+// REST requests send it as the X-Databricks-Correlation-Id header, and submitted statements
+// are prefixed with a /* correlation_id=... */ comment so the ID can be found in
+// system.query.history.statement_text.
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	ctx = context.WithValue(ctx, correlationIDKey{}, id)
+	return driverctx.NewContextWithCorrelationId(ctx, id)
+}
+
+// correlationID returns the ID set by WithCorrelationID, falling back to one set directly with
+// driverctx.NewContextWithCorrelationId
+func correlationID(ctx context.Context) string {
+	if id, ok := ctx.Value(correlationIDKey{}).(string); ok {
+		return id
+	}
+	return driverctx.CorrelationIdFromContext(ctx)
+}
+
+// setCorrelationHeader adds the correlation ID header when ctx carries one
+func setCorrelationHeader(ctx context.Context, header http.Header) {
+	if id := correlationID(ctx); id != "" {
+		header.Set(correlationIDHeader, id)
+	}
+}
+
+// tagStatement prefixes a statement with a comment holding the correlation ID. The ID is kept
+// on one line and cannot close the comment early.
+func tagStatement(ctx context.Context, statement string) string {
+	id := correlationID(ctx)
+	if id == "" {
+		return statement
+	}
+	id = strings.NewReplacer("*/", "* /", "\n", " ", "\r", " ").Replace(id)
+	return "/* correlation_id=" + id + " */ " + statement
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// CredentialProvider supplies the bearer token for each request, so credentials can rotate
+// without rebuilding the client. Token is called before every request and may be called
+// concurrently; providers that fetch tokens remotely should cache them.
+type CredentialProvider interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// StaticTokenProvider always returns the same token, e.g. a personal access token
+type StaticTokenProvider string
+
+// Token returns the static token
+func (p StaticTokenProvider) Token(context.Context) (string, error) {
+	return string(p), nil
+}
+
+// EnvTokenProvider reads the token from an environment variable on every request, so a token
+// rotated into the environment is picked up without a restart
+type EnvTokenProvider struct {
+	// Name is the variable to read; defaults to DATABRICKS_TOKEN
+	Name string
+}
+
+// Token returns the variable's current value, failing if it is unset or empty
+func (p EnvTokenProvider) Token(context.Context) (string, error) {
+	name := p.Name
+	if name == "" {
+		name = "DATABRICKS_TOKEN"
+	}
+	token := os.Getenv(name)
+	if token == "" {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return token, nil
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"sync"
+	"time"
+
+	dbsql "github.com/databricks/databricks-sql-go"
+)
+
+// Connection pool defaults for a SQL warehouse. A warehouse cluster runs about 10 queries at a
+// time and queues the rest, so more open connections only add sessions that wait. Opening a
+// session costs a round trip to the warehouse, so idle connections are kept up to the open limit,
+// and connections are recycled periodically so no session outlives the warehouse's idle expiry.
+const (
+	defaultMaxOpenConns    = 10
+	defaultConnMaxLifetime = 30 * time.Minute
+)
+
+// DBOptions tunes the sql.DB returned by OpenDB. Zero fields use the defaults.
+type DBOptions struct {
+	// MaxOpenConns caps concurrent sessions; defaults to 10, one warehouse cluster's concurrency.
+	// Raise it by 10 per cluster for warehouses that scale out.
+	MaxOpenConns int
+	// MaxIdleConns caps sessions kept open between queries; defaults to MaxOpenConns
+	MaxIdleConns int
+	// ConnMaxLifetime recycles sessions after this long; defaults to 30 minutes
+	ConnMaxLifetime time.Duration
+	// SessionParams are set on every new session, e.g. {"timezone": "UTC", "ansi_mode": "true"},
+	// saving a SET statement per connection
+	SessionParams map[string]string
+}
+
+// OpenDB opens a connection pool to the configured warehouse through the SQL driver, sized for
+// a warehouse's limited concurrency rather than database/sql's unlimited default. A config with
+// only a WarehouseName is resolved to an ID when the pool opens its first connection.
+func OpenDB(cfg Config, opts DBOptions) (*sql.DB, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	var connector driver.Connector
+	if cfg.WarehouseID != "" {
+		var err error
+		if connector, err = newConnector(cfg, opts); err != nil {
+			return nil, err
+		}
+	} else {
+		// The driver is all database/sql needs before the first connection
+		placeholder, err := newConnector(cfg, opts)
+		if err != nil {
+			return nil, err
+		}
+		connector = &lazyConnector{cfg: cfg, opts: opts, driver: placeholder.Driver()}
+	}
+
+	maxOpen := opts.MaxOpenConns
+	if maxOpen <= 0 {
+		maxOpen = defaultMaxOpenConns
+	}
+	maxIdle := opts.MaxIdleConns
+	if maxIdle <= 0 {
+		maxIdle = maxOpen
+	}
+	lifetime := opts.ConnMaxLifetime
+	if lifetime <= 0 {
+		lifetime = defaultConnMaxLifetime
+	}
+
+	db := sql.OpenDB(connector)
+	db.SetMaxOpenConns(maxOpen)
+	db.SetMaxIdleConns(maxIdle)
+	db.SetConnMaxLifetime(lifetime)
+	return db, nil
+}
+
+func newConnector(cfg Config, opts DBOptions) (driver.Connector, error) {
+	connOpts := []dbsql.ConnOption{
+		dbsql.WithServerHostname(cfg.Hostname),
+		dbsql.WithPort(cfg.port()),
+		dbsql.WithAccessToken(cfg.Token),
+		dbsql.WithHTTPPath("/sql/1.0/endpoints/" + cfg.WarehouseID),
+	}
+	if cfg.Catalog != "" {
+		connOpts = append(connOpts, dbsql.WithInitialNamespace(cfg.Catalog, cfg.Schema))
+	}
+	if len(opts.SessionParams) > 0 {
+		connOpts = append(connOpts, dbsql.WithSessionParams(opts.SessionParams))
+	}
+	connector, err := dbsql.NewConnector(connOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create connector: %w", err)
+	}
+	return connector, nil
+}
+
+// lazyConnector resolves Config.WarehouseName on the first connection, since OpenDB has no
+// context for the lookup. A failed lookup is retried by the next connection attempt.
+type lazyConnector struct {
+	cfg    Config
+	opts   DBOptions
+	driver driver.Driver
+
+	mu        sync.Mutex
+	connector driver.Connector
+}
+
+func (l *lazyConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	l.mu.Lock()
+	if l.connector == nil {
+		cfg, err := l.cfg.Resolve(ctx)
+		if err == nil {
+			l.connector, err = newConnector(cfg, l.opts)
+		}
+		if err != nil {
+			l.mu.Unlock()
+			return nil, err
+		}
+	}
+	connector := l.connector
+	l.mu.Unlock()
+	return connector.Connect(ctx)
+}
+
+func (l *lazyConnector) Driver() driver.Driver {
+	return l.driver
+}
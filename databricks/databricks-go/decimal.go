@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	decimalType = reflect.TypeOf(Decimal{})
+	bigRatType  = reflect.TypeOf(big.Rat{})
+)
+
+// Decimal is a DECIMAL value kept as the exact text the API returned, with the column's
+// precision and scale when known. DECIMAL(38,18) holds more digits than float64's roughly 15,
+// so converting through float64 silently rounds.
+type Decimal struct {
+	Value     string
+	Precision int
+	Scale     int
+}
+
+// String returns the exact decimal text
+func (d Decimal) String() string {
+	return d.Value
+}
+
+// Rat returns the exact value as a *big.Rat
+func (d Decimal) Rat() (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(d.Value)
+	if !ok {
+		return nil, fmt.Errorf("invalid decimal %q", d.Value)
+	}
+	return r, nil
+}
+
+// Float64 returns the nearest float64, which loses precision beyond about 15 significant digits
+func (d Decimal) Float64() (float64, error) {
+	f, err := strconv.ParseFloat(d.Value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid decimal %q", d.Value)
+	}
+	return f, nil
+}
+
+// MarshalJSON writes the value as a JSON number with every digit intact. The zero Decimal, which
+// holds no value, marshals to null.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	if d.Value == "" {
+		return []byte("null"), nil
+	}
+	return []byte(d.Value), nil
+}
+
+// ExactDecimalConverter is DefaultTypeConverter except that DECIMAL cells decode to Decimal,
+// carrying the precision and scale from the manifest, instead of float64. Set it as the client's
+// Converter to decode DECIMAL columns exactly.
+func ExactDecimalConverter(col Column, cell *string) (any, error) {
+	if col.TypeName != "DECIMAL" || cell == nil {
+		return DefaultTypeConverter(col, cell)
+	}
+	d, err := columnDecimal(col, *cell)
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// columnDecimal validates value and pairs it with col's precision and scale, taken from the
+// manifest's type_precision and type_scale or, when those are absent, its type_text
+func columnDecimal(col Column, value string) (Decimal, error) {
+	if _, ok := new(big.Rat).SetString(value); !ok {
+		return Decimal{}, fmt.Errorf("invalid DECIMAL value %q", value)
+	}
+	d := Decimal{Value: value, Precision: col.TypePrecision, Scale: col.TypeScale}
+	if d.Precision == 0 {
+		d.Precision, d.Scale = decimalPrecisionScale(col.TypeText)
+	}
+	return d, nil
+}
+
+// decimalPrecisionScale parses the precision and scale out of a type_text such as
+// "DECIMAL(38,18)", returning zeros when it has none
+func decimalPrecisionScale(typeText string) (precision, scale int) {
+	_, args, ok := strings.Cut(typeText, "(")
+	if !ok {
+		return 0, 0
+	}
+	args, _, _ = strings.Cut(args, ")")
+	p, s, _ := strings.Cut(args, ",")
+	precision, _ = strconv.Atoi(strings.TrimSpace(p))
+	scale, _ = strconv.Atoi(strings.TrimSpace(s))
+	return precision, scale
+}
+
+// assignDecimal fills a big.Rat or Decimal field from a cell's exact text, giving a Decimal the
+// precision and scale of col. It reports false for any other field type.
+func assignDecimal(field reflect.Value, col Column, value string) (bool, error) {
+	switch field.Type() {
+	case bigRatType:
+		if _, ok := field.Addr().Interface().(*big.Rat).SetString(value); !ok {
+			return true, fmt.Errorf("invalid decimal %q", value)
+		}
+		return true, nil
+	case decimalType:
+		d, err := columnDecimal(col, value)
+		if err != nil {
+			return true, err
+		}
+		field.Set(reflect.ValueOf(d))
+		return true, nil
+	}
+	return false, nil
+}
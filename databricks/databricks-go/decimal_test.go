@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"databricks-go-timing-test/testserver"
+)
+
+// maxDecimal is the largest DECIMAL(38,0) value, 38 nines
+const maxDecimal = "99999999999999999999999999999999999999"
+
+func TestExactDecimalConverter(t *testing.T) {
+	tests := []struct {
+		name             string
+		col              Column
+		cell             string
+		precision, scale int
+	}{
+		{
+			name:      "38 integer digits",
+			col:       Column{TypeName: "DECIMAL", TypeText: "DECIMAL(38,0)"},
+			cell:      maxDecimal,
+			precision: 38,
+		},
+		{
+			name:      "38 digits with scale 18",
+			col:       Column{TypeName: "DECIMAL", TypeText: "DECIMAL(38,18)"},
+			cell:      "-12345678901234567890.123456789012345678",
+			precision: 38,
+			scale:     18,
+		},
+		{
+			name:      "precision and scale from the manifest",
+			col:       Column{TypeName: "DECIMAL", TypePrecision: 20, TypeScale: 19},
+			cell:      "0.1000000000000000001",
+			precision: 20,
+			scale:     19,
+		},
+		{
+			name:      "negative scale",
+			col:       Column{TypeName: "DECIMAL", TypeText: "DECIMAL(5,-3)"},
+			cell:      "12345000",
+			precision: 5,
+			scale:     -3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cell := tt.cell
+			got, err := ExactDecimalConverter(tt.col, &cell)
+			if err != nil {
+				t.Fatal(err)
+			}
+			d, ok := got.(Decimal)
+			if !ok {
+				t.Fatalf("got %T, want Decimal", got)
+			}
+			if d.Value != tt.cell || d.Precision != tt.precision || d.Scale != tt.scale {
+				t.Errorf("got %+v, want %s with precision %d and scale %d", d, tt.cell, tt.precision, tt.scale)
+			}
+
+			// Every digit survives Rat and JSON, unlike a float64 round trip
+			r, err := d.Rat()
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, _ := new(big.Rat).SetString(tt.cell)
+			if r.Cmp(want) != 0 {
+				t.Errorf("Rat() = %s, want %s", r.FloatString(20), want.FloatString(20))
+			}
+			data, err := json.Marshal(d)
+			if err != nil || string(data) != tt.cell {
+				t.Errorf("json.Marshal = %s, %v, want %s", data, err, tt.cell)
+			}
+		})
+	}
+}
+
+func TestExactDecimalConverterBeyondFloat64(t *testing.T) {
+	// Each value has more significant digits than float64 holds exactly
+	for _, cell := range []string{"9007199254740993", maxDecimal, "0.12345678901234567890123456789012345678"} {
+		got, err := ExactDecimalConverter(Column{TypeName: "DECIMAL"}, &cell)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d := got.(Decimal)
+		exact, _ := new(big.Rat).SetString(cell)
+		if r, err := d.Rat(); err != nil || r.Cmp(exact) != 0 {
+			t.Errorf("%s: Rat() = %v, %v, want the exact value", cell, r, err)
+		}
+
+		// DefaultTypeConverter goes through float64 and rounds the same value
+		rounded, err := DefaultTypeConverter(Column{TypeName: "DECIMAL"}, &cell)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if new(big.Rat).SetFloat64(rounded.(float64)).Cmp(exact) == 0 {
+			t.Errorf("%s survives float64; the test needs a value it cannot hold", cell)
+		}
+	}
+}
+
+func TestExactDecimalConverterOtherTypes(t *testing.T) {
+	cell := "42"
+	if got, err := ExactDecimalConverter(Column{TypeName: "BIGINT"}, &cell); err != nil || got != int64(42) {
+		t.Errorf("BIGINT = %#v, %v, want 42", got, err)
+	}
+	if got, err := ExactDecimalConverter(Column{TypeName: "DECIMAL"}, nil); err != nil || got != nil {
+		t.Errorf("DECIMAL NULL = %#v, %v, want nil", got, err)
+	}
+	bad := "1.2.3"
+	if _, err := ExactDecimalConverter(Column{TypeName: "DECIMAL"}, &bad); err == nil {
+		t.Error("invalid DECIMAL was accepted")
+	}
+}
+
+func TestDecimalZeroValue(t *testing.T) {
+	var d Decimal
+	data, err := json.Marshal(struct {
+		Amount Decimal  `json:"amount"`
+		Fee    *Decimal `json:"fee"`
+	}{Amount: d})
+	if err != nil {
+		t.Fatalf("json.Marshal of a struct holding a zero Decimal: %v", err)
+	}
+	if string(data) != `{"amount":null,"fee":null}` {
+		t.Errorf("json.Marshal = %s", data)
+	}
+	if _, err := d.Rat(); err == nil {
+		t.Error("Rat() of the zero Decimal succeeded")
+	}
+	if _, err := d.Float64(); err == nil {
+		t.Error("Float64() of the zero Decimal succeeded")
+	}
+}
+
+func TestScanRowsDecimal(t *testing.T) {
+	cell := maxDecimal + ".5"
+	resp := &StatementExecutionResponse{
+		Manifest: Manifest{Schema: Schema{ColumnCount: 1, Columns: []Column{{Name: "amount", TypeName: "DECIMAL"}}}},
+		Result:   ResultData{DataArray: [][]*string{{&cell}, {nil}}},
+	}
+	var rows []struct {
+		Rat     big.Rat  `databricks:"amount"`
+		Decimal Decimal  `databricks:"amount"`
+		Ptr     *Decimal `databricks:"amount"`
+	}
+	if err := ScanRows(resp, &rows); err != nil {
+		t.Fatal(err)
+	}
+	want, _ := new(big.Rat).SetString(cell)
+	if rows[0].Rat.Cmp(want) != 0 || rows[0].Decimal.Value != cell || rows[0].Ptr == nil || rows[0].Ptr.Value != cell {
+		t.Errorf("row 0 = %s / %+v / %+v, want %s", rows[0].Rat.FloatString(1), rows[0].Decimal, rows[0].Ptr, cell)
+	}
+	if rows[1].Ptr != nil || rows[1].Decimal.Value != "" {
+		t.Errorf("NULL row = %+v / %+v, want zero values", rows[1].Decimal, rows[1].Ptr)
+	}
+}
+
+// TestScanDecimalMatchesConverter checks that scanning into a Decimal field gives the same value,
+// precision and scale as ExactDecimalConverter, whether the manifest carries type_precision and
+// type_scale or only type_text
+func TestScanDecimalMatchesConverter(t *testing.T) {
+	cell := "-12345678901234567890.123456789012345678"
+	for _, col := range []Column{
+		{Name: "amount", TypeName: "DECIMAL", TypeText: "DECIMAL(38,18)"},
+		{Name: "amount", TypeName: "DECIMAL", TypeText: "DECIMAL(38,18)", TypePrecision: 38, TypeScale: 18},
+	} {
+		converted, err := ExactDecimalConverter(col, &cell)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp := &StatementExecutionResponse{
+			Manifest: Manifest{Schema: Schema{ColumnCount: 1, Columns: []Column{col}}},
+			Result:   ResultData{DataArray: [][]*string{{&cell}}},
+		}
+		var rows []struct {
+			Decimal Decimal  `databricks:"amount"`
+			Ptr     *Decimal `databricks:"amount"`
+		}
+		if err := ScanRows(resp, &rows); err != nil {
+			t.Fatal(err)
+		}
+		if rows[0].Decimal != converted || *rows[0].Ptr != converted {
+			t.Errorf("ScanRows = %+v / %+v, converter = %+v", rows[0].Decimal, *rows[0].Ptr, converted)
+		}
+	}
+
+	client, handler := newTestClient(t)
+	handler.AddStatement(testserver.Statement{
+		Text:    "SELECT amount",
+		Rows:    [][]*string{{&cell}},
+		Columns: []testserver.Column{{Name: "amount", TypeName: "DECIMAL", TypeText: "DECIMAL(38,18)"}},
+	})
+	rows, err := client.QueryRows(context.Background(), "SELECT amount")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		t.Fatalf("no rows: %v", rows.Err())
+	}
+	var d Decimal
+	if err := rows.Scan(&d); err != nil {
+		t.Fatal(err)
+	}
+	if want := (Decimal{Value: cell, Precision: 38, Scale: 18}); d != want {
+		t.Errorf("RestRows.Scan = %+v, want %+v", d, want)
+	}
+}
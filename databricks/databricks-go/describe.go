@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// DescribeStatement returns the columns and types a query produces without materializing its
+// rows. The query is wrapped as SELECT * FROM (...) WHERE 1=0, so the warehouse plans it and
+// reports the manifest but returns nothing. Only row-producing queries can be described.
+func (c *DatabricksRESTClient) DescribeStatement(ctx context.Context, statement string) (*Schema, error) {
+	query, err := selectFrom(statement)
+	if err != nil {
+		return nil, err
+	}
+	_, resp, err := c.executeStatement(ctx, c.newStatementRequest(query+" WHERE 1=0"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe %q: %w", summarizeStatement(statement), err)
+	}
+	return &resp.Manifest.Schema, nil
+}
+
+// selectFrom wraps a query as SELECT * FROM (...) so clauses can be appended to it. Trailing
+// semicolons are dropped, and the newlines keep a trailing -- comment in the query from
+// swallowing the closing paren.
+func selectFrom(statement string) (string, error) {
+	query := strings.TrimRight(strings.TrimSpace(statement), "; \t\n")
+	if query == "" {
+		return "", fmt.Errorf("empty statement")
+	}
+	return "SELECT * FROM (\n" + query + "\n)", nil
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrSchemaMismatch is returned by DiffResults when the two results' columns differ, so their
+// rows can't be compared
+var ErrSchemaMismatch = errors.New("result schemas differ")
+
+// maxDiffSamples is how many differing rows ResultDiff keeps
+const maxDiffSamples = 20
+
+// Kinds of RowDiff
+const (
+	RowAdded   = "added"
+	RowRemoved = "removed"
+	RowChanged = "changed"
+)
+
+// RowDiff is one differing row. Before is nil for added rows and After is nil for removed rows.
+type RowDiff struct {
+	Kind   string
+	Before []*string
+	After  []*string
+}
+
+// ResultDiff summarizes how result b differs from result a
+type ResultDiff struct {
+	Added, Removed, Changed, Unchanged int
+	// Samples holds up to the first 20 differing rows
+	Samples []RowDiff
+}
+
+// Equal reports whether the results had the same rows
+func (d *ResultDiff) Equal() bool {
+	return d.Added == 0 && d.Removed == 0 && d.Changed == 0
+}
+
+func (d *ResultDiff) record(kind string, before, after []*string) {
+	switch kind {
+	case RowAdded:
+		d.Added++
+	case RowRemoved:
+		d.Removed++
+	case RowChanged:
+		d.Changed++
+	}
+	if len(d.Samples) < maxDiffSamples {
+		d.Samples = append(d.Samples, RowDiff{Kind: kind, Before: before, After: after})
+	}
+}
+
+// DiffResults compares two fully materialized results, such as those from ExecuteStatement,
+// by their raw cell values. The schemas must match in column names and types, otherwise an error
+// wrapping ErrSchemaMismatch is returned. Without keyColumns rows are compared by position, so
+// both statements need an ORDER BY. With keyColumns rows are matched by those columns regardless
+// of order, a row with the same key but other values counts as changed, and the samples are in
+// key order.
+func DiffResults(a, b *StatementExecutionResponse, keyColumns ...string) (*ResultDiff, error) {
+	if err := compareSchemas(a.Manifest.Schema, b.Manifest.Schema); err != nil {
+		return nil, err
+	}
+	if len(keyColumns) == 0 {
+		return diffByPosition(a.Result.DataArray, b.Result.DataArray), nil
+	}
+
+	keys := make([]int, len(keyColumns))
+	for i, name := range keyColumns {
+		col, ok := a.Manifest.Schema.ColumnIndex(name)
+		if !ok {
+			return nil, fmt.Errorf("diff: key column %q is not in the result", name)
+		}
+		keys[i] = col
+	}
+	return diffByKey(a.Result.DataArray, b.Result.DataArray, keys)
+}
+
+func compareSchemas(a, b Schema) error {
+	if len(a.Columns) != len(b.Columns) {
+		return fmt.Errorf("%w: %d columns vs %d", ErrSchemaMismatch, len(a.Columns), len(b.Columns))
+	}
+	for i := range a.Columns {
+		ca, cb := a.Columns[i], b.Columns[i]
+		if ca.Name != cb.Name || columnType(ca) != columnType(cb) {
+			return fmt.Errorf("%w: column %d is %s %s vs %s %s", ErrSchemaMismatch, i, ca.Name, columnType(ca), cb.Name, columnType(cb))
+		}
+	}
+	return nil
+}
+
+// columnType prefers the full SQL type so DECIMAL(10,2) and DECIMAL(38,18) are told apart
+func columnType(col Column) string {
+	if col.TypeText != "" {
+		return strings.ToUpper(col.TypeText)
+	}
+	return strings.ToUpper(col.TypeName)
+}
+
+func diffByPosition(a, b [][]*string) *ResultDiff {
+	diff := &ResultDiff{}
+	for i := range max(len(a), len(b)) {
+		switch {
+		case i >= len(a):
+			diff.record(RowAdded, nil, b[i])
+		case i >= len(b):
+			diff.record(RowRemoved, a[i], nil)
+		case !slices.EqualFunc(a[i], b[i], cellsEqual):
+			diff.record(RowChanged, a[i], b[i])
+		default:
+			diff.Unchanged++
+		}
+	}
+	return diff
+}
+
+func diffByKey(a, b [][]*string, keys []int) (*ResultDiff, error) {
+	before, err := indexRows(a, keys)
+	if err != nil {
+		return nil, fmt.Errorf("diff: first result: %w", err)
+	}
+	after, err := indexRows(b, keys)
+	if err != nil {
+		return nil, fmt.Errorf("diff: second result: %w", err)
+	}
+
+	all := make([]string, 0, len(before)+len(after))
+	for key := range before {
+		all = append(all, key)
+	}
+	for key := range after {
+		if _, ok := before[key]; !ok {
+			all = append(all, key)
+		}
+	}
+	slices.Sort(all)
+
+	diff := &ResultDiff{}
+	for _, key := range all {
+		rowA, inA := before[key]
+		rowB, inB := after[key]
+		switch {
+		case !inA:
+			diff.record(RowAdded, nil, rowB)
+		case !inB:
+			diff.record(RowRemoved, rowA, nil)
+		case !slices.EqualFunc(rowA, rowB, cellsEqual):
+			diff.record(RowChanged, rowA, rowB)
+		default:
+			diff.Unchanged++
+		}
+	}
+	return diff, nil
+}
+
+// indexRows maps each row's key to the row; a key that is not unique is an error
+func indexRows(rows [][]*string, keys []int) (map[string][]*string, error) {
+	index := make(map[string][]*string, len(rows))
+	for i, row := range rows {
+		key := rowKey(row, keys)
+		if _, ok := index[key]; ok {
+			return nil, fmt.Errorf("row %d repeats a key; key columns must be unique", i)
+		}
+		index[key] = row
+	}
+	return index, nil
+}
+
+// rowKey encodes the key cells so that NULL, "" and values containing the separator stay distinct
+func rowKey(row []*string, keys []int) string {
+	var b strings.Builder
+	for _, col := range keys {
+		if col >= len(row) || row[col] == nil {
+			b.WriteString("N;")
+			continue
+		}
+		fmt.Fprintf(&b, "%d:%s;", len(*row[col]), *row[col])
+	}
+	return b.String()
+}
+
+func cellsEqual(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/databricks/databricks-sql-go/driverctx"
+)
+
+// ExecResult holds the IDs captured from the SQL driver together with driver-side timing
+type ExecResult struct {
+	QueryID, ConnID string
+	Timing          TimingInfo
+}
+
+// ExecuteWithIDs runs a query through the SQL driver with query-ID and connection-ID callbacks
+// installed, drains the rows, and returns both IDs with the driver-side timing
+func ExecuteWithIDs(ctx context.Context, db *sql.DB, query string) (ExecResult, error) {
+	var (
+		mu     sync.Mutex
+		result ExecResult
+	)
+	ctx = driverctx.NewContextWithQueryIdCallback(ctx, func(id string) {
+		mu.Lock()
+		defer mu.Unlock()
+		result.QueryID = id
+	})
+	ctx = driverctx.NewContextWithConnIdCallback(ctx, func(id string) {
+		mu.Lock()
+		defer mu.Unlock()
+		result.ConnID = id
+	})
+
+	startTime := time.Now()
+	rowCount, columnCount, err := drainQuery(ctx, db, query)
+	endTime := time.Now()
+
+	mu.Lock()
+	defer mu.Unlock()
+	result.Timing = TimingInfo{
+		Method:        MethodGoDriver,
+		QueryID:       result.QueryID,
+		StatementText: query,
+		StartTime:     startTime,
+		EndTime:       endTime,
+		DurationMs:    endTime.Sub(startTime).Milliseconds(),
+		RowCount:      rowCount,
+		ColumnCount:   columnCount,
+	}
+	if err != nil {
+		result.Timing.ErrorMessage = err.Error()
+		return result, err
+	}
+	return result, nil
+}
+
+// drainQuery runs a query and reads every row without scanning values
+func drainQuery(ctx context.Context, db *sql.DB, query string) (int64, int, error) {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read columns: %w", err)
+	}
+
+	var rowCount int64
+	for rows.Next() {
+		rowCount++
+	}
+	if err := rows.Err(); err != nil {
+		return rowCount, len(columns), fmt.Errorf("failed to read rows: %w", err)
+	}
+	return rowCount, len(columns), nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	dbsqlrows "github.com/databricks/databricks-sql-go/rows"
+)
+
+// QueryArrowBatches runs a query on a dedicated connection and reads the result as Arrow record
+// batches instead of scanning row by row. database/sql does not expose the driver rows behind a
+// *sql.Rows, so the query is issued through sql.Conn.Raw. Callers must Release each record.
+func QueryArrowBatches(ctx context.Context, db *sql.DB, query string) ([]arrow.Record, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	var records []arrow.Record
+	err = conn.Raw(func(driverConn any) error {
+		queryer, ok := driverConn.(driver.QueryerContext)
+		if !ok {
+			return fmt.Errorf("driver connection %T does not support QueryContext", driverConn)
+		}
+		rows, err := queryer.QueryContext(ctx, query, nil)
+		if err != nil {
+			return fmt.Errorf("failed to execute query: %w", err)
+		}
+		defer rows.Close()
+
+		records, err = ReadArrowBatches(ctx, rows)
+		return err
+	})
+	return records, err
+}
+
+// ReadArrowBatches pulls every Arrow record batch from databricks-sql-go driver rows.
+// It returns an error if rows do not come from the Databricks driver or the result is not
+// Arrow-backed. Callers must Release each record.
+func ReadArrowBatches(ctx context.Context, rows driver.Rows) ([]arrow.Record, error) {
+	arrowRows, ok := rows.(dbsqlrows.Rows)
+	if !ok {
+		return nil, fmt.Errorf("driver rows %T do not support Arrow batches", rows)
+	}
+
+	batches, err := arrowRows.GetArrowBatches(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("result does not support Arrow batches: %w", err)
+	}
+	defer batches.Close()
+
+	var records []arrow.Record
+	for {
+		record, err := batches.Next()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil {
+			releaseRecords(records)
+			return nil, fmt.Errorf("failed to read Arrow batch %d: %w", len(records), err)
+		}
+		records = append(records, record)
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ParseDSN parses a token:<token>@<host>:<port>/sql/1.0/endpoints/<id> DSN back into a Config,
+// reporting which part is missing or malformed. The default port 443 is returned as zero, so
+// ParseDSN(c.DSN()) yields c for any valid Config that leaves Port unset.
+func ParseDSN(dsn string) (Config, error) {
+	u, err := parseDSNURL(dsn)
+	if err != nil {
+		return Config{}, err
+	}
+
+	token, ok := u.User.Password()
+	if u.User.Username() != "token" || !ok || token == "" {
+		return Config{}, fmt.Errorf("invalid DSN: missing token")
+	}
+	if u.Port() == "" {
+		return Config{}, fmt.Errorf("invalid DSN: missing port")
+	}
+	port, err := strconv.Atoi(u.Port())
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid DSN: invalid port %q", u.Port())
+	}
+	if port == defaultPort {
+		port = 0
+	}
+	warehouseID, err := warehouseIDFromPath(u.Path)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid DSN: endpoint id not found in path %q", u.Path)
+	}
+
+	cfg := Config{
+		Token:       token,
+		Hostname:    u.Hostname(),
+		WarehouseID: warehouseID,
+		Port:        port,
+		Catalog:     u.Query().Get("catalog"),
+		Schema:      u.Query().Get("schema"),
+	}
+	if err := cfg.validate(); err != nil {
+		return Config{}, fmt.Errorf("invalid DSN: %w", err)
+	}
+	return cfg, nil
+}
+
+// ParseWarehouseIDFromDSN extracts the warehouse ID from the HTTP path of a DSN, accepting both
+// the /sql/1.0/endpoints/{id} and /sql/1.0/warehouses/{id} forms
+func ParseWarehouseIDFromDSN(dsn string) (string, error) {
+	u, err := parseDSNURL(dsn)
+	if err != nil {
+		return "", err
+	}
+	return warehouseIDFromPath(u.Path)
+}
+
+// NewClientFromDSN creates a REST client whose hostname, token and warehouse all come from a
+// single token-authenticated DSN, so the same values are not supplied twice. A port other than
+// 443 is kept, so the client reaches the same endpoint as the Config from ParseDSN.
+func NewClientFromDSN(dsn string, opts ...ClientOption) (*DatabricksRESTClient, error) {
+	u, err := parseDSNURL(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	warehouseID, err := warehouseIDFromPath(u.Path)
+	if err != nil {
+		return nil, err
+	}
+	token, ok := u.User.Password()
+	if u.User.Username() != "token" || !ok || token == "" {
+		return nil, fmt.Errorf("DSN does not contain a token credential")
+	}
+
+	cfg := Config{
+		Token:       token,
+		Hostname:    u.Hostname(),
+		WarehouseID: warehouseID,
+		Catalog:     u.Query().Get("catalog"),
+		Schema:      u.Query().Get("schema"),
+	}
+	if u.Port() != "" {
+		if cfg.Port, err = strconv.Atoi(u.Port()); err != nil {
+			return nil, fmt.Errorf("invalid DSN: invalid port %q", u.Port())
+		}
+	}
+	// The DSN's namespace comes first so that opts can override it
+	return NewDatabricksRESTClient(cfg.host(), cfg.Token, cfg.WarehouseID, append(cfg.ClientOptions(), opts...)...), nil
+}
+
+// parseDSNURL parses a DSN the way databricks-sql-go does, treating a scheme-less DSN as https
+func parseDSNURL(dsn string) (*url.URL, error) {
+	full := dsn
+	if !strings.HasPrefix(dsn, "https://") && !strings.HasPrefix(dsn, "http://") {
+		full = "https://" + dsn
+	}
+	u, err := url.Parse(full)
+	if err != nil {
+		// *url.Error repeats the whole DSN, token included, so report only the cause
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("invalid DSN: %w", err)
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid DSN: missing hostname")
+	}
+	return u, nil
+}
+
+func warehouseIDFromPath(path string) (string, error) {
+	for _, prefix := range []string{"/sql/1.0/endpoints/", "/sql/1.0/warehouses/"} {
+		if id, ok := strings.CutPrefix(path, prefix); ok {
+			id = strings.TrimSuffix(id, "/")
+			if id == "" || strings.Contains(id, "/") {
+				break
+			}
+			return id, nil
+		}
+	}
+	return "", fmt.Errorf("warehouse ID not found in DSN path %q", path)
+}
@@ -0,0 +1,20 @@
+package main
+
+import "time"
+
+// epochMsToTime converts epoch milliseconds to a time.Time. Zero, which the history API returns
+// for timestamps it has not recorded, maps to the zero time rather than 1970-01-01.
+func epochMsToTime(ms int64) time.Time {
+	if ms == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms)
+}
+
+// timeToEpochMs is the inverse of epochMsToTime: the zero time maps to 0
+func timeToEpochMs(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestEpochRoundTrip(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		t    time.Time
+	}{
+		// Clocks jump from 02:00 EST to 03:00 EDT on 2024-03-10
+		{"before spring forward", time.Date(2024, 3, 10, 1, 59, 59, 999e6, newYork)},
+		{"after spring forward", time.Date(2024, 3, 10, 3, 0, 0, 1e6, newYork)},
+		// 01:30 happens twice on 2024-11-03, first in EDT and an hour later in EST
+		{"fall back, first 01:30", time.Date(2024, 11, 3, 5, 30, 0, 0, time.UTC).In(newYork)},
+		{"fall back, second 01:30", time.Date(2024, 11, 3, 6, 30, 0, 0, time.UTC).In(newYork)},
+		{"negative epoch", time.Date(1969, 12, 31, 23, 59, 59, 999e6, time.UTC).In(newYork)},
+		{"far past", time.Date(1900, 1, 1, 0, 0, 0, 0, newYork)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms := timeToEpochMs(tt.t)
+			if ms != tt.t.UnixMilli() {
+				t.Errorf("timeToEpochMs(%v) = %d, want %d", tt.t, ms, tt.t.UnixMilli())
+			}
+			if got := epochMsToTime(ms); !got.Equal(tt.t) {
+				t.Errorf("epochMsToTime(%d) = %v, want %v", ms, got, tt.t)
+			}
+		})
+	}
+
+	// The two 01:30s of the fall-back day are an hour apart, not the same instant
+	first := timeToEpochMs(tests[2].t)
+	second := timeToEpochMs(tests[3].t)
+	if second-first != time.Hour.Milliseconds() {
+		t.Errorf("fall-back 01:30s are %dms apart, want %d", second-first, time.Hour.Milliseconds())
+	}
+}
+
+func TestEpochZero(t *testing.T) {
+	if got := epochMsToTime(0); !got.IsZero() {
+		t.Errorf("epochMsToTime(0) = %v, want the zero time", got)
+	}
+	if got := timeToEpochMs(time.Time{}); got != 0 {
+		t.Errorf("timeToEpochMs(zero) = %d, want 0", got)
+	}
+	if got := epochMsToTime(-1); !got.Equal(time.Date(1969, 12, 31, 23, 59, 59, 999e6, time.UTC)) {
+		t.Errorf("epochMsToTime(-1) = %v", got)
+	}
+	// The Unix epoch itself converts to 0, the same as an unrecorded timestamp
+	if got := timeToEpochMs(time.Unix(0, 0)); got != 0 {
+		t.Errorf("timeToEpochMs(1970-01-01) = %d, want 0", got)
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Bounds the Statement Execution API accepts for wait_timeout
+const (
+	minWaitTimeout = 5 * time.Second
+	maxWaitTimeout = 50 * time.Second
+)
+
+// waitTimeoutFor fits wait_timeout to ctx's deadline so a synchronous submit never outlives the
+// caller: the time left, capped at configured and at 50s and floored at 5s. With less than 5s
+// left it returns "0s" and async is true, and the caller must poll for the result instead.
+// Without a deadline, or when configured is already "0s", configured is returned unchanged.
+func waitTimeoutFor(ctx context.Context, configured string) (waitTimeout string, async bool) {
+	deadline, ok := ctx.Deadline()
+	if !ok || configured == "0s" {
+		return configured, false
+	}
+	remaining := time.Until(deadline)
+	if remaining < minWaitTimeout {
+		return "0s", true
+	}
+
+	wait := min(remaining, maxWaitTimeout)
+	if d, err := time.ParseDuration(configured); err == nil && d > 0 {
+		wait = min(wait, d)
+	}
+	wait = max(wait.Truncate(time.Second), minWaitTimeout)
+	return fmt.Sprintf("%ds", int(wait/time.Second)), false
+}
+
+// ExecOptions overrides how a statement is submitted. Zero fields keep the defaults: a 50s wait,
+// INLINE disposition, JSON_ARRAY format and no byte or row limit.
+type ExecOptions struct {
+	// WaitTimeout is how long the API holds the request open; it must be between 5s and 50s
+	WaitTimeout time.Duration
+	// Disposition is DispositionInline or DispositionExternalLinks
+	Disposition string
+	// Format is FormatJSONArray, FormatArrowStream or FormatCSV
+	Format    string
+	ByteLimit int64
+	RowLimit  int64
+	// Tags are attached to the statement as a leading comment so they appear in
+	// system.query.history.statement_text for cost attribution; read them back with
+	// QueryHistoryResponse.Tags. They do not change the statement's semantics.
+	Tags map[string]string
+	// AutoDisposition picks INLINE or EXTERNAL_LINKS from the EXPLAIN COST size estimate. It
+	// cannot be combined with Disposition; the choice is reported in TimingInfo.Disposition.
+	AutoDisposition bool
+	// Catalog and Schema resolve unqualified table names in the statement. They must be set
+	// together and override the client's WithNamespace default.
+	Catalog string
+	Schema  string
+	// SessionConfs are Spark and SQL confs, e.g. {"use_cached_result": "false"}, applied with SET
+	// in a short-lived session that the statement then runs in, so they affect only this
+	// statement. They need a warehouse that supports sessions; see OpenSession. Values are
+	// limited to letters, digits and _ . : / + -, which covers booleans, sizes and time zones.
+	SessionConfs map[string]string
+	// FallbackFormat, e.g. FormatJSONArray, is retried once when the warehouse rejects Format as
+	// unsupported; TimingInfo.Format reports which format served the result
+	FallbackFormat string
+}
+
+// inlineSizeLimit is the largest estimated result AutoDisposition sends INLINE, leaving headroom
+// under the API's 25 MiB INLINE cap for the estimate's error
+const inlineSizeLimit = 16 << 20
+
+// ExecuteStatementWithOptions runs a statement like ExecuteStatementWithREST with the wait
+// timeout, disposition, format and limits taken from opts
+func (c *DatabricksRESTClient) ExecuteStatementWithOptions(ctx context.Context, statement string, opts ExecOptions) (*TimingInfo, error) {
+	if opts.AutoDisposition {
+		if opts.Disposition != "" {
+			return nil, fmt.Errorf("AutoDisposition cannot be combined with disposition %s", opts.Disposition)
+		}
+		opts.Disposition = c.chooseDisposition(ctx, statement, opts.Format)
+	}
+
+	reqBody, err := c.newStatementRequestWithOptions(statement, opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(opts.SessionConfs) > 0 {
+		sessionID, closeSession, err := c.openConfiguredSession(ctx, opts.SessionConfs)
+		if err != nil {
+			return nil, err
+		}
+		defer closeSession()
+		reqBody.SessionID = sessionID
+	}
+	timing, _, err := c.executeStatement(ctx, reqBody)
+	if err == nil || opts.FallbackFormat == "" || opts.FallbackFormat == reqBody.Format || !isUnsupportedFormat(err) {
+		return timing, err
+	}
+
+	c.logger.WarnContext(ctx, "result format not supported, falling back",
+		slog.String("format", reqBody.Format),
+		slog.String("fallback_format", opts.FallbackFormat),
+		slog.String("error", err.Error()))
+	opts.Format = opts.FallbackFormat
+	sessionID := reqBody.SessionID
+	if reqBody, err = c.newStatementRequestWithOptions(statement, opts); err != nil {
+		return nil, fmt.Errorf("invalid fallback format: %w", err)
+	}
+	reqBody.SessionID = sessionID
+	timing, _, err = c.executeStatement(ctx, reqBody)
+	return timing, err
+}
+
+// isUnsupportedFormat reports whether the API rejected a statement because of its result format
+func isUnsupportedFormat(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.ErrorCode {
+	case "UNSUPPORTED_FORMAT", "NOT_IMPLEMENTED":
+		return true
+	case "INVALID_PARAMETER_VALUE", "BAD_REQUEST":
+		return strings.Contains(strings.ToLower(apiErr.Message), "format")
+	}
+	return apiErr.StatusCode == http.StatusNotImplemented
+}
+
+// newStatementRequestWithOptions builds the default request and applies validated opts to it
+func (c *DatabricksRESTClient) newStatementRequestWithOptions(statement string, opts ExecOptions) (StatementExecutionRequest, error) {
+	reqBody := c.newStatementRequest(tagComment(opts.Tags) + statement)
+
+	if opts.WaitTimeout != 0 {
+		if opts.WaitTimeout < minWaitTimeout || opts.WaitTimeout > maxWaitTimeout {
+			return reqBody, fmt.Errorf("wait timeout %s is outside the allowed range of %s to %s", opts.WaitTimeout, minWaitTimeout, maxWaitTimeout)
+		}
+		reqBody.WaitTimeout = fmt.Sprintf("%ds", int(opts.WaitTimeout/time.Second))
+	}
+
+	switch opts.Disposition {
+	case "":
+	case DispositionInline, DispositionExternalLinks:
+		reqBody.Disposition = opts.Disposition
+	default:
+		return reqBody, fmt.Errorf("unsupported disposition %q", opts.Disposition)
+	}
+
+	switch opts.Format {
+	case "":
+	case FormatJSONArray, FormatArrowStream, FormatCSV:
+		reqBody.Format = opts.Format
+	default:
+		return reqBody, fmt.Errorf("unsupported format %q", opts.Format)
+	}
+	if reqBody.Disposition == DispositionInline && reqBody.Format != FormatJSONArray {
+		return reqBody, fmt.Errorf("format %s requires the %s disposition", reqBody.Format, DispositionExternalLinks)
+	}
+
+	if err := validateNamespace(opts.Catalog, opts.Schema); err != nil {
+		return reqBody, err
+	}
+	if opts.Catalog != "" {
+		reqBody.Catalog = opts.Catalog
+		reqBody.Schema = opts.Schema
+	}
+
+	if opts.ByteLimit < 0 || opts.RowLimit < 0 {
+		return reqBody, fmt.Errorf("invalid byte limit %d or row limit %d", opts.ByteLimit, opts.RowLimit)
+	}
+	reqBody.ByteLimit = opts.ByteLimit
+	reqBody.RowLimit = opts.RowLimit
+	return reqBody, nil
+}
+
+// chooseDisposition returns INLINE when EXPLAIN COST estimates a small JSON_ARRAY result and
+// EXTERNAL_LINKS otherwise, including when no estimate is available (e.g. for DDL)
+func (c *DatabricksRESTClient) chooseDisposition(ctx context.Context, statement, format string) string {
+	if format != "" && format != FormatJSONArray {
+		return DispositionExternalLinks
+	}
+	estimate, err := c.ExplainCost(ctx, statement)
+	if err != nil || estimate.SizeInBytes > inlineSizeLimit {
+		return DispositionExternalLinks
+	}
+	return DispositionInline
+}
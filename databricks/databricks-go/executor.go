@@ -0,0 +1,13 @@
+package main
+
+import "context"
+
+// StatementExecutor is the subset of DatabricksRESTClient needed to run and track statements.
+// Code that accepts it can be tested with a stub instead of a workspace or mock server.
+type StatementExecutor interface {
+	ExecuteStatementWithREST(ctx context.Context, statement string) (*TimingInfo, error)
+	GetStatementTiming(ctx context.Context, statementID string) (*TimingInfo, error)
+	CancelStatement(ctx context.Context, statementID string) error
+}
+
+var _ StatementExecutor = (*DatabricksRESTClient)(nil)
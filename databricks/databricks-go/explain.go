@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// CostEstimate is the optimizer's estimate for the root of a plan, from EXPLAIN COST
+type CostEstimate struct {
+	// SizeInBytes is the estimated output size
+	SizeInBytes float64
+	// RowCount is the estimated output rows; it is -1 when the optimizer has no row estimate
+	RowCount int64
+	// Plan is the full EXPLAIN COST output
+	Plan string
+}
+
+// statisticsPattern matches the Statistics(...) annotation EXPLAIN COST attaches to each plan node
+var statisticsPattern = regexp.MustCompile(`Statistics\(sizeInBytes=([0-9.Ee+-]+) ?([KMGTPE]i)?B(?:, rowCount=([0-9.Ee+-]+))?`)
+
+// sizeUnits maps the binary unit prefixes used by Spark's size formatting to multipliers
+var sizeUnits = map[string]float64{
+	"":   1,
+	"Ki": 1 << 10,
+	"Mi": 1 << 20,
+	"Gi": 1 << 30,
+	"Ti": 1 << 40,
+	"Pi": 1 << 50,
+	"Ei": 1 << 60,
+}
+
+// ExplainStatement runs EXPLAIN FORMATTED on a statement without executing it and returns the plan
+func (c *DatabricksRESTClient) ExplainStatement(ctx context.Context, statement string) (string, error) {
+	return c.explain(ctx, "EXPLAIN FORMATTED ", statement)
+}
+
+// ExplainCost runs EXPLAIN COST on a statement and returns the optimizer's size and row
+// estimates for the plan's root node
+func (c *DatabricksRESTClient) ExplainCost(ctx context.Context, statement string) (CostEstimate, error) {
+	plan, err := c.explain(ctx, "EXPLAIN COST ", statement)
+	if err != nil {
+		return CostEstimate{}, err
+	}
+
+	// The optimized plan is printed root first, so the first annotation is the overall estimate
+	match := statisticsPattern.FindStringSubmatch(plan)
+	if match == nil {
+		return CostEstimate{}, fmt.Errorf("no cost statistics in plan for %q", summarizeStatement(statement))
+	}
+	size, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return CostEstimate{}, fmt.Errorf("invalid sizeInBytes %q: %w", match[1], err)
+	}
+	estimate := CostEstimate{SizeInBytes: size * sizeUnits[match[2]], RowCount: -1, Plan: plan}
+	if match[3] != "" {
+		rows, err := strconv.ParseFloat(match[3], 64)
+		if err != nil {
+			return CostEstimate{}, fmt.Errorf("invalid rowCount %q: %w", match[3], err)
+		}
+		estimate.RowCount = int64(rows)
+	}
+	return estimate, nil
+}
+
+// explain runs statement under the given EXPLAIN prefix and joins the returned plan rows
+func (c *DatabricksRESTClient) explain(ctx context.Context, prefix, statement string) (string, error) {
+	resp, _, err := c.ExecuteStatement(ctx, prefix+statement)
+	if err != nil {
+		return "", fmt.Errorf("failed to explain %q: %w", summarizeStatement(statement), err)
+	}
+
+	lines := make([]string, 0, len(resp.Result.DataArray))
+	for _, row := range resp.Result.DataArray {
+		if len(row) > 0 && row[0] != nil {
+			lines = append(lines, *row[0])
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const defaultExportPageSize = 1000
+
+// ExportHistory calls fn for every system.query.history row with start_time in [start, end),
+// ordered by start_time and statement_id. Rows are fetched pageSize at a time using keyset
+// pagination on (start_time, statement_id), so rows sharing a start_time are neither skipped nor
+// repeated across page boundaries. A non-positive pageSize uses 1000. If fn returns an error,
+// the export stops and that error is returned.
+func (c *DatabricksRESTClient) ExportHistory(ctx context.Context, start, end time.Time, pageSize int, fn func(QueryHistoryResponse) error) error {
+	if pageSize <= 0 {
+		pageSize = defaultExportPageSize
+	}
+
+	base := fmt.Sprintf("SELECT %s FROM system.query.history WHERE start_time >= %s AND start_time < %s",
+		queryHistoryColumns, sqlTimestamp(start), sqlTimestamp(end))
+	order := fmt.Sprintf(" ORDER BY start_time, statement_id LIMIT %d", pageSize)
+
+	query := base + order
+	var params []StatementParameter
+	for page := 0; ; page++ {
+		history, err := c.queryHistory(ctx, query, params...)
+		if err != nil {
+			return fmt.Errorf("failed to export history page %d: %w", page, err)
+		}
+		for _, h := range history {
+			if err := fn(h); err != nil {
+				return err
+			}
+		}
+		if len(history) < pageSize {
+			return nil
+		}
+
+		last := history[len(history)-1]
+		query = fmt.Sprintf("%s AND (start_time > %s OR (start_time = %s AND statement_id > :last_id))%s",
+			base, sqlTimestamp(last.StartTime), sqlTimestamp(last.StartTime), order)
+		params = []StatementParameter{{Name: "last_id", Value: last.StatementID}}
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// ErrExternalLinkExpired is returned when a presigned URL has expired before it could be downloaded
+var ErrExternalLinkExpired = errors.New("external link expired")
+
+// ExternalLink is a presigned URL for one chunk of an EXTERNAL_LINKS result
+type ExternalLink struct {
+	ExternalLink          string            `json:"external_link"`
+	ChunkIndex            int               `json:"chunk_index"`
+	RowOffset             int64             `json:"row_offset"`
+	RowCount              int64             `json:"row_count"`
+	ByteCount             int64             `json:"byte_count"`
+	Expiration            time.Time         `json:"expiration"`
+	HTTPHeaders           map[string]string `json:"http_headers,omitempty"`
+	NextChunkIndex        *int              `json:"next_chunk_index,omitempty"`
+	NextChunkInternalLink string            `json:"next_chunk_internal_link,omitempty"`
+}
+
+// DownloadExternalLinks downloads and decodes JSON_ARRAY chunks from presigned URLs.
+// Without a manifest schema every non-null cell is returned as a string.
+func (c *DatabricksRESTClient) DownloadExternalLinks(ctx context.Context, links []ExternalLink) ([][]any, error) {
+	return c.downloadExternalLinks(ctx, Schema{}, links)
+}
+
+func (c *DatabricksRESTClient) downloadExternalLinks(ctx context.Context, schema Schema, links []ExternalLink) ([][]any, error) {
+	rows := [][]any{}
+	for _, link := range links {
+		data, err := c.downloadExternalLink(ctx, link)
+		if err != nil {
+			return nil, err
+		}
+
+		decoded, err := decodeRows(schema, data, c.typeConverter())
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode chunk %d: %w", link.ChunkIndex, err)
+		}
+		rows = append(rows, decoded...)
+	}
+	return rows, nil
+}
+
+// downloadExternalLink fetches and parses a single JSON_ARRAY chunk
+func (c *DatabricksRESTClient) downloadExternalLink(ctx context.Context, link ExternalLink) ([][]*string, error) {
+	body, err := c.fetchExternalLink(ctx, link)
+	if err != nil {
+		return nil, err
+	}
+
+	var data [][]*string
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, fmt.Errorf("failed to parse chunk %d: %w", link.ChunkIndex, err)
+	}
+	return data, nil
+}
+
+// fetchExternalLink downloads the raw bytes behind a presigned URL, retrying transport failures
+// and retryable statuses per the client's retry policy
+func (c *DatabricksRESTClient) fetchExternalLink(ctx context.Context, link ExternalLink) ([]byte, error) {
+	startTime := time.Now()
+	for attempt := 0; ; attempt++ {
+		body, err := c.fetchExternalLinkOnce(ctx, link)
+		if err == nil {
+			c.retryBudget.success()
+			recordTransfer(ctx, len(body), true, time.Since(startTime))
+			return body, nil
+		}
+		if attempt >= c.MaxRetries || ctx.Err() != nil || errors.Is(err, ErrExternalLinkExpired) {
+			return body, err
+		}
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && !apiErr.IsRetryable() {
+			return nil, err
+		}
+		if !c.allowRetry(ctx, "external link") {
+			return nil, err
+		}
+		if err := sleepContext(ctx, c.retryDelay(attempt, nil)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// fetchExternalLinkOnce makes a single download attempt. The URL is already signed, so the
+// workspace Authorization header must not be sent to the cloud storage provider.
+func (c *DatabricksRESTClient) fetchExternalLinkOnce(ctx context.Context, link ExternalLink) ([]byte, error) {
+	if !link.Expiration.IsZero() && time.Now().After(link.Expiration) {
+		return nil, fmt.Errorf("chunk %d: %w at %s", link.ChunkIndex, ErrExternalLinkExpired, link.Expiration.Format(time.RFC3339))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", link.ExternalLink, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request for chunk %d: %w", link.ChunkIndex, err)
+	}
+	for name, value := range link.HTTPHeaders {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := c.doHTTP(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download chunk %d: %w", link.ChunkIndex, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chunk %d: %w", link.ChunkIndex, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download chunk %d: %w", link.ChunkIndex, newAPIError(resp.StatusCode, body))
+	}
+	return body, nil
+}
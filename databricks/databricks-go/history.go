@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// ErrNotYetInHistory is returned when a statement has not materialized in query history yet.
+// system.query.history rows typically lag completion by several minutes, so callers should retry later.
+var ErrNotYetInHistory = errors.New("statement not yet in query history")
+
+// QueryHistoryResponse is a single row of system.query.history
+type QueryHistoryResponse struct {
+	StatementID           string    `json:"statement_id"`
+	StatementText         string    `json:"statement_text"`
+	ExecutionStatus       string    `json:"execution_status"`
+	WarehouseID           string    `json:"warehouse_id"`
+	StartTime             time.Time `json:"start_time"`
+	EndTime               time.Time `json:"end_time"`
+	TotalDurationMs       int64     `json:"total_duration_ms"`
+	ExecutionDurationMs   int64     `json:"execution_duration_ms"`
+	CompilationDurationMs int64     `json:"compilation_duration_ms"`
+	ReadRows              int64     `json:"read_rows"`
+	ProducedRows          int64     `json:"produced_rows"`
+	ReadBytes             int64     `json:"read_bytes"`
+	WrittenBytes          int64     `json:"written_bytes"`
+	SpilledLocalBytes     int64     `json:"spilled_local_bytes"`
+	// WaitingForComputeMs is time spent waiting for the warehouse to start or scale up
+	WaitingForComputeMs int64 `json:"waiting_for_compute_duration_ms"`
+	// WaitingAtCapacityMs is time spent queued because the warehouse was at capacity
+	WaitingAtCapacityMs   int64 `json:"waiting_at_capacity_duration_ms"`
+	ResultFetchDurationMs int64 `json:"result_fetch_duration_ms"`
+	ReadFiles             int64 `json:"read_files"`
+	PrunedFiles           int64 `json:"pruned_files"`
+	ReadPartitions        int64 `json:"read_partitions"`
+	// FromResultCache is set when the result was served from the result cache without executing
+	FromResultCache bool `json:"from_result_cache"`
+}
+
+// coldStartThresholdMs is the compute wait above which a query is counted as a cold start
+const coldStartThresholdMs = 1000
+
+// WasColdStart reports whether the query waited more than a second for compute, i.e. its
+// latency includes warehouse start-up rather than only query execution
+func (q QueryHistoryResponse) WasColdStart() bool {
+	return q.WaitingForComputeMs > coldStartThresholdMs
+}
+
+// ReadThroughputMBps returns read_bytes per second of execution, in megabytes (10^6 bytes).
+// It returns 0 when the execution duration is zero, e.g. for results served from cache.
+func (q QueryHistoryResponse) ReadThroughputMBps() float64 {
+	if q.ExecutionDurationMs <= 0 {
+		return 0
+	}
+	return float64(q.ReadBytes) / 1e6 / (float64(q.ExecutionDurationMs) / 1000)
+}
+
+// TimingInfo renders the history row as a QUERY_HISTORY timing record using the server-side
+// duration and produced row count; State carries the execution status
+func (q QueryHistoryResponse) TimingInfo() TimingInfo {
+	return TimingInfo{
+		Method:        MethodQueryHistory,
+		QueryID:       q.StatementID,
+		StatementText: q.StatementText,
+		StartTime:     q.StartTime,
+		EndTime:       q.EndTime,
+		DurationMs:    q.TotalDurationMs,
+		State:         q.ExecutionStatus,
+		RowCount:      q.ProducedRows,
+	}
+}
+
+// queryHistoryColumns is the select list that parseQueryHistoryRows understands
+const queryHistoryColumns = `statement_id, statement_text, execution_status,
+	compute.warehouse_id AS warehouse_id, start_time, end_time,
+	total_duration_ms, execution_duration_ms, compilation_duration_ms,
+	read_rows, produced_rows, read_bytes, written_bytes, spilled_local_bytes,
+	waiting_for_compute_duration_ms, waiting_at_capacity_duration_ms, result_fetch_duration_ms,
+	read_files, pruned_files, read_partitions, from_result_cache`
+
+// statementIDPattern matches statement/query IDs, which are UUIDs
+var statementIDPattern = regexp.MustCompile(`^[0-9a-fA-F-]+$`)
+
+// QueryHistoryByID looks up the system.query.history row for an exact statement ID, as captured
+// by the driver's query-ID callback. It returns ErrNotYetInHistory if the row does not exist yet.
+func (c *DatabricksRESTClient) QueryHistoryByID(ctx context.Context, statementID string) (*QueryHistoryResponse, error) {
+	if !statementIDPattern.MatchString(statementID) {
+		return nil, fmt.Errorf("invalid statement ID %q", statementID)
+	}
+	if cached, ok := cachedHistory[QueryHistoryResponse](c, cacheKeyHistoryTable+statementID); ok {
+		return cached, nil
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM system.query.history WHERE statement_id = :statement_id", queryHistoryColumns)
+	history, err := c.queryHistory(ctx, query, StatementParameter{Name: "statement_id", Value: statementID})
+	if err != nil {
+		return nil, err
+	}
+	if len(history) == 0 {
+		return nil, fmt.Errorf("statement %s: %w", statementID, ErrNotYetInHistory)
+	}
+	c.checkPhases(ctx, statementID, history[0].Phases(), history[0].TotalDurationMs)
+	cacheHistory(c, cacheKeyHistoryTable+statementID, history[0].ExecutionStatus, &history[0])
+	return &history[0], nil
+}
+
+// QueryHistoryForStatement returns every system.query.history row whose statement text contains
+// statementText, most recent first.
+//
+// Deprecated: substring matching returns unrelated queries that share text. Use QueryHistoryByID
+// with the statement ID captured from the driver instead.
+func (c *DatabricksRESTClient) QueryHistoryForStatement(ctx context.Context, statementText string) ([]QueryHistoryResponse, error) {
+	query := fmt.Sprintf("SELECT %s FROM system.query.history WHERE contains(statement_text, :stmt) ORDER BY start_time DESC", queryHistoryColumns)
+	return c.queryHistory(ctx, query, StatementParameter{Name: "stmt", Value: statementText})
+}
+
+// queryHistory runs a query against system.query.history, binding any params, and parses every
+// returned row
+func (c *DatabricksRESTClient) queryHistory(ctx context.Context, query string, params ...StatementParameter) ([]QueryHistoryResponse, error) {
+	schema, rows, err := c.query(ctx, query, params...)
+	if err != nil {
+		return nil, fmt.Errorf("query history lookup failed: %w", err)
+	}
+	return parseQueryHistoryRows(schema, rows)
+}
+
+// query runs a metadata query with bound params and returns every decoded row with its schema
+func (c *DatabricksRESTClient) query(ctx context.Context, query string, params ...StatementParameter) (Schema, [][]any, error) {
+	reqBody := c.newStatementRequest(query)
+	reqBody.Parameters = params
+	timing, resp, err := c.executeStatement(ctx, reqBody)
+	if err != nil {
+		return Schema{}, nil, err
+	}
+	if resp.Status.State != StateSucceeded {
+		return Schema{}, nil, fmt.Errorf("statement %s ended in state %s: %s", resp.StatementID, resp.Status.State, timing.ErrorMessage)
+	}
+
+	rows, err := c.followChunks(ctx, resp.StatementID, resp.Manifest, &resp.Result)
+	if err != nil {
+		return Schema{}, nil, err
+	}
+	return resp.Manifest.Schema, rows, nil
+}
+
+// parseQueryHistoryRows maps decoded history rows onto QueryHistoryResponse by column name
+func parseQueryHistoryRows(schema Schema, rows [][]any) ([]QueryHistoryResponse, error) {
+	index := columnIndexes(schema)
+	history := make([]QueryHistoryResponse, 0, len(rows))
+	for _, row := range rows {
+		r := namedRow{index: index, row: row}
+		h := QueryHistoryResponse{
+			StatementID:     r.String("statement_id"),
+			StatementText:   r.String("statement_text"),
+			ExecutionStatus: r.String("execution_status"),
+			WarehouseID:     r.String("warehouse_id"),
+		}
+
+		var err error
+		if h.StartTime, err = r.Time("start_time"); err != nil {
+			return nil, err
+		}
+		if h.EndTime, err = r.Time("end_time"); err != nil {
+			return nil, err
+		}
+		if h.TotalDurationMs, err = r.Int64("total_duration_ms"); err != nil {
+			return nil, err
+		}
+		if h.ExecutionDurationMs, err = r.Int64("execution_duration_ms"); err != nil {
+			return nil, err
+		}
+		if h.CompilationDurationMs, err = r.Int64("compilation_duration_ms"); err != nil {
+			return nil, err
+		}
+		if h.ReadRows, err = r.Int64("read_rows"); err != nil {
+			return nil, err
+		}
+		if h.ProducedRows, err = r.Int64("produced_rows"); err != nil {
+			return nil, err
+		}
+		if h.ReadBytes, err = r.Int64("read_bytes"); err != nil {
+			return nil, err
+		}
+		if h.WrittenBytes, err = r.Int64("written_bytes"); err != nil {
+			return nil, err
+		}
+		if h.SpilledLocalBytes, err = r.Int64("spilled_local_bytes"); err != nil {
+			return nil, err
+		}
+		if h.WaitingForComputeMs, err = r.Int64("waiting_for_compute_duration_ms"); err != nil {
+			return nil, err
+		}
+		if h.WaitingAtCapacityMs, err = r.Int64("waiting_at_capacity_duration_ms"); err != nil {
+			return nil, err
+		}
+		if h.ResultFetchDurationMs, err = r.Int64("result_fetch_duration_ms"); err != nil {
+			return nil, err
+		}
+		if h.ReadFiles, err = r.Int64("read_files"); err != nil {
+			return nil, err
+		}
+		if h.PrunedFiles, err = r.Int64("pruned_files"); err != nil {
+			return nil, err
+		}
+		if h.ReadPartitions, err = r.Int64("read_partitions"); err != nil {
+			return nil, err
+		}
+		if h.FromResultCache, err = r.Bool("from_result_cache"); err != nil {
+			return nil, err
+		}
+		history = append(history, h)
+	}
+	return history, nil
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	historyPollInterval    = 250 * time.Millisecond
+	maxHistoryPollInterval = 2 * time.Second
+)
+
+// HistoryQueryResponse is the response of the undocumented /api/2.0/sql/history/queries/{id}
+// endpoint. Fields the endpoint omits are left at their zero values.
+type HistoryQueryResponse struct {
+	QueryID         string `json:"query_id"`
+	Status          string `json:"status"`
+	QueryText       string `json:"query_text"`
+	StatementType   string `json:"statement_type"`
+	UserID          int64  `json:"user_id"`
+	UserName        string `json:"user_name"`
+	UserDisplayName string `json:"user_display_name"`
+	EndpointID      string `json:"endpoint_id"`
+	WarehouseID     string `json:"warehouse_id"`
+
+	// Epoch-millisecond timestamps as returned by the endpoint
+	QueryStartTimeMs   int64                `json:"query_start_time_ms"`
+	ExecutionEndTimeMs int64                `json:"execution_end_time_ms"`
+	QueryEndTimeMs     int64                `json:"query_end_time_ms"`
+	DurationMs         int64                `json:"duration"`
+	RowsProduced       int64                `json:"rows_produced"`
+	ClientApplication  string               `json:"client_application"`
+	ChannelUsed        *HistoryChannel      `json:"channel_used,omitempty"`
+	PlansState         string               `json:"plans_state"`
+	IsFinal            bool                 `json:"is_final"`
+	IsCancelable       bool                 `json:"is_cancelable"`
+	CanSubscribeToLive bool                 `json:"canSubscribeToLiveQuery"`
+	Metrics            *HistoryQueryMetrics `json:"metrics,omitempty"`
+
+	// StartTime, ExecutionEndTime and EndTime are the epoch-millisecond fields converted to
+	// time.Time; they are zero when the endpoint reports 0 or omits the field
+	StartTime        time.Time `json:"-"`
+	ExecutionEndTime time.Time `json:"-"`
+	EndTime          time.Time `json:"-"`
+}
+
+// HistoryChannel identifies the DBSQL channel and version that ran the query
+type HistoryChannel struct {
+	Name         string `json:"name"`
+	DBSQLVersion string `json:"dbsql_version"`
+}
+
+// HistoryQueryMetrics holds the detailed phase timings and I/O counters of a query
+type HistoryQueryMetrics struct {
+	TotalTimeMs         int64 `json:"total_time_ms"`
+	CompilationTimeMs   int64 `json:"compilation_time_ms"`
+	PlanningTimeMs      int64 `json:"planning_time_ms"`
+	ExecutionTimeMs     int64 `json:"execution_time_ms"`
+	ResultFetchTimeMs   int64 `json:"result_fetch_time_ms"`
+	PhotonTotalTimeMs   int64 `json:"photon_total_time_ms"`
+	TaskTotalTimeMs     int64 `json:"task_total_time_ms"`
+	ReadBytes           int64 `json:"read_bytes"`
+	ReadRemoteBytes     int64 `json:"read_remote_bytes"`
+	ReadCacheBytes      int64 `json:"read_cache_bytes"`
+	WriteRemoteBytes    int64 `json:"write_remote_bytes"`
+	NetworkSentBytes    int64 `json:"network_sent_bytes"`
+	SpillToDiskBytes    int64 `json:"spill_to_disk_bytes"`
+	RowsReadCount       int64 `json:"rows_read_count"`
+	RowsProducedCount   int64 `json:"rows_produced_count"`
+	ReadFilesCount      int64 `json:"read_files_count"`
+	ReadPartitionsCount int64 `json:"read_partitions_count"`
+	PrunedFilesCount    int64 `json:"pruned_files_count"`
+	PrunedBytes         int64 `json:"pruned_bytes"`
+	ResultFromCache     bool  `json:"result_from_cache"`
+
+	// Epoch-millisecond timestamps of the queuing and compilation milestones
+	OverloadingQueueStartTimestamp  int64 `json:"overloading_queue_start_timestamp"`
+	ProvisioningQueueStartTimestamp int64 `json:"provisioning_queue_start_timestamp"`
+	QueryCompilationStartTimestamp  int64 `json:"query_compilation_start_timestamp"`
+}
+
+// UnmarshalJSON decodes the response and fills StartTime, ExecutionEndTime and EndTime from
+// their epoch-millisecond fields
+func (h *HistoryQueryResponse) UnmarshalJSON(data []byte) error {
+	type plain HistoryQueryResponse
+	if err := json.Unmarshal(data, (*plain)(h)); err != nil {
+		return err
+	}
+	h.StartTime = epochMsToTime(h.QueryStartTimeMs)
+	h.ExecutionEndTime = epochMsToTime(h.ExecutionEndTimeMs)
+	h.EndTime = epochMsToTime(h.QueryEndTimeMs)
+	return nil
+}
+
+// GetHistoryQuery fetches server-side timing for a statement from the undocumented
+// /api/2.0/sql/history/queries/{id} endpoint, which is available immediately after completion
+func (c *DatabricksRESTClient) GetHistoryQuery(ctx context.Context, statementID string) (*HistoryQueryResponse, error) {
+	if cached, ok := cachedHistory[HistoryQueryResponse](c, cacheKeyHistoryAPI+statementID); ok {
+		return cached, nil
+	}
+
+	var resp HistoryQueryResponse
+	if err := c.doJSON(ctx, "GET", "/api/2.0/sql/history/queries/"+statementID, nil, &resp); err != nil {
+		return nil, fmt.Errorf("failed to get history for query %s: %w", statementID, err)
+	}
+	if resp.Metrics != nil {
+		c.checkPhases(ctx, statementID, resp.Phases(), resp.Metrics.TotalTimeMs)
+	}
+	cacheHistory(c, cacheKeyHistoryAPI+statementID, resp.Status, &resp)
+	return &resp, nil
+}
+
+// GetHistoryQueryWithRetry polls GetHistoryQuery until the history entry for a statement exists.
+// A 404 or RESOURCE_DOES_NOT_EXIST means the entry has not materialized yet and is retried with
+// backoff; any other error is returned immediately. If the entry is still missing after maxWait,
+// the returned error wraps ErrNotYetInHistory.
+func (c *DatabricksRESTClient) GetHistoryQueryWithRetry(ctx context.Context, statementID string, maxWait time.Duration) (*HistoryQueryResponse, error) {
+	deadline := time.Now().Add(maxWait)
+	interval := historyPollInterval
+	for {
+		resp, err := c.GetHistoryQuery(ctx, statementID)
+		if err == nil {
+			return resp, nil
+		}
+		if !isHistoryNotReady(err) {
+			return nil, err
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return nil, fmt.Errorf("statement %s not in history after %s: %w", statementID, maxWait, ErrNotYetInHistory)
+		}
+		if err := sleepContext(ctx, min(interval, remaining)); err != nil {
+			return nil, fmt.Errorf("stopped waiting for history of statement %s: %w", statementID, err)
+		}
+		interval = min(interval*2, maxHistoryPollInterval)
+	}
+}
+
+// isHistoryNotReady reports whether a history lookup failed only because the entry does not exist yet
+func isHistoryNotReady(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.StatusCode == http.StatusNotFound || apiErr.ErrorCode == "RESOURCE_DOES_NOT_EXIST"
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"databricks-go-timing-test/testserver"
+)
+
+const testStatementID = "01f08938-cb0b-1cab-8942-4fad144663d3"
+
+func TestGetHistoryQuery(t *testing.T) {
+	client, handler := newTestClient(t)
+	handler.AddHistoryQuery(testStatementID, map[string]any{
+		"query_id":              testStatementID,
+		"status":                "FINISHED",
+		"query_text":            "SELECT 42",
+		"query_start_time_ms":   1756953746624,
+		"execution_end_time_ms": 1756953746700,
+		"query_end_time_ms":     1756953746739,
+		"duration":              115,
+		"rows_produced":         1,
+		"metrics": map[string]any{
+			"total_time_ms":     115,
+			"execution_time_ms": 60,
+			"read_bytes":        2048,
+		},
+	}, 0)
+
+	resp, err := client.GetHistoryQuery(context.Background(), testStatementID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Status != "FINISHED" || resp.DurationMs != 115 || resp.RowsProduced != 1 {
+		t.Errorf("resp = %+v", resp)
+	}
+	if !resp.StartTime.Equal(time.UnixMilli(1756953746624)) || !resp.EndTime.Equal(time.UnixMilli(1756953746739)) {
+		t.Errorf("start/end = %v/%v", resp.StartTime, resp.EndTime)
+	}
+	if resp.Metrics == nil || resp.Metrics.ExecutionTimeMs != 60 || resp.Metrics.ReadBytes != 2048 {
+		t.Errorf("metrics = %+v", resp.Metrics)
+	}
+}
+
+func TestGetHistoryQueryWithRetry(t *testing.T) {
+	client, handler := newTestClient(t)
+	handler.AddHistoryQuery(testStatementID, map[string]any{"query_id": testStatementID, "status": "FINISHED"}, 2)
+
+	if _, err := client.GetHistoryQuery(context.Background(), testStatementID); !isHistoryNotReady(err) {
+		t.Fatalf("first lookup err = %v, want a not-ready error", err)
+	}
+	resp, err := client.GetHistoryQueryWithRetry(context.Background(), testStatementID, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.QueryID != testStatementID {
+		t.Errorf("query ID = %s", resp.QueryID)
+	}
+
+	_, err = client.GetHistoryQueryWithRetry(context.Background(), "00000000-0000-0000-0000-000000000000", 100*time.Millisecond)
+	if !errors.Is(err, ErrNotYetInHistory) {
+		t.Errorf("err = %v, want ErrNotYetInHistory", err)
+	}
+}
+
+// historyColumns is the schema of a system.query.history lookup in queryHistoryColumns order
+var historyColumns = []testserver.Column{
+	{Name: "statement_id", TypeName: "STRING"},
+	{Name: "statement_text", TypeName: "STRING"},
+	{Name: "execution_status", TypeName: "STRING"},
+	{Name: "warehouse_id", TypeName: "STRING"},
+	{Name: "start_time", TypeName: "TIMESTAMP"},
+	{Name: "end_time", TypeName: "TIMESTAMP"},
+	{Name: "total_duration_ms", TypeName: "BIGINT"},
+	{Name: "execution_duration_ms", TypeName: "BIGINT"},
+	{Name: "compilation_duration_ms", TypeName: "BIGINT"},
+	{Name: "read_rows", TypeName: "BIGINT"},
+	{Name: "produced_rows", TypeName: "BIGINT"},
+	{Name: "read_bytes", TypeName: "BIGINT"},
+	{Name: "written_bytes", TypeName: "BIGINT"},
+	{Name: "spilled_local_bytes", TypeName: "BIGINT"},
+	{Name: "waiting_for_compute_duration_ms", TypeName: "BIGINT"},
+	{Name: "waiting_at_capacity_duration_ms", TypeName: "BIGINT"},
+	{Name: "result_fetch_duration_ms", TypeName: "BIGINT"},
+	{Name: "read_files", TypeName: "BIGINT"},
+	{Name: "pruned_files", TypeName: "BIGINT"},
+	{Name: "read_partitions", TypeName: "BIGINT"},
+	{Name: "from_result_cache", TypeName: "BOOLEAN"},
+}
+
+func historyRow(values ...string) []*string {
+	row := make([]*string, len(values))
+	for i := range values {
+		row[i] = &values[i]
+	}
+	return row
+}
+
+func TestQueryHistoryByID(t *testing.T) {
+	client, handler := newTestClient(t)
+	query := fmt.Sprintf("SELECT %s FROM system.query.history WHERE statement_id = :statement_id", queryHistoryColumns)
+	handler.AddStatement(testserver.Statement{
+		Text:    query,
+		Columns: historyColumns,
+		Rows: [][]*string{historyRow(testStatementID, "SELECT 42", "FINISHED", "warehouse",
+			"2025-09-04T02:42:26.624Z", "2025-09-04T02:42:26.739Z",
+			"115", "60", "30", "1000", "1", "4000000", "0", "0", "2500", "0", "5", "4", "2", "1", "false")},
+	})
+
+	h, err := client.QueryHistoryByID(context.Background(), testStatementID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := QueryHistoryResponse{
+		StatementID:           testStatementID,
+		StatementText:         "SELECT 42",
+		ExecutionStatus:       "FINISHED",
+		WarehouseID:           "warehouse",
+		StartTime:             time.Date(2025, 9, 4, 2, 42, 26, 624e6, time.UTC),
+		EndTime:               time.Date(2025, 9, 4, 2, 42, 26, 739e6, time.UTC),
+		TotalDurationMs:       115,
+		ExecutionDurationMs:   60,
+		CompilationDurationMs: 30,
+		ReadRows:              1000,
+		ProducedRows:          1,
+		ReadBytes:             4000000,
+		WaitingForComputeMs:   2500,
+		ResultFetchDurationMs: 5,
+		ReadFiles:             4,
+		PrunedFiles:           2,
+		ReadPartitions:        1,
+	}
+	if !h.StartTime.Equal(want.StartTime) || !h.EndTime.Equal(want.EndTime) {
+		t.Errorf("start/end = %v/%v, want %v/%v", h.StartTime, h.EndTime, want.StartTime, want.EndTime)
+	}
+	h.StartTime, h.EndTime = want.StartTime, want.EndTime
+	if *h != want {
+		t.Errorf("history = %+v\nwant %+v", *h, want)
+	}
+	if !h.WasColdStart() || h.ReadThroughputMBps() != 4000000/1e6/0.06 {
+		t.Errorf("cold start = %v, throughput = %v", h.WasColdStart(), h.ReadThroughputMBps())
+	}
+	// The ID travels as a bound parameter, never inside the SQL text
+	if execs := handler.Executions(); len(execs) != 1 || execs[0].Parameters["statement_id"] != testStatementID {
+		t.Errorf("executions = %+v, want statement_id bound to %s", execs, testStatementID)
+	}
+}
+
+func TestQueryHistoryByIDNotYetInHistory(t *testing.T) {
+	client, handler := newTestClient(t)
+	query := fmt.Sprintf("SELECT %s FROM system.query.history WHERE statement_id = :statement_id", queryHistoryColumns)
+	handler.AddStatement(testserver.Statement{Text: query, Columns: historyColumns})
+
+	if _, err := client.QueryHistoryByID(context.Background(), testStatementID); !errors.Is(err, ErrNotYetInHistory) {
+		t.Errorf("err = %v, want ErrNotYetInHistory", err)
+	}
+	if _, err := client.QueryHistoryByID(context.Background(), "'; DROP TABLE x; --"); err == nil {
+		t.Error("malformed statement ID was accepted")
+	}
+}
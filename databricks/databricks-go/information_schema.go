@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+// TableInfo is a row of system.information_schema.tables
+type TableInfo struct {
+	Catalog          string
+	Schema           string
+	Name             string
+	TableType        string
+	DataSourceFormat string
+}
+
+// ColumnInfo is a row of system.information_schema.columns
+type ColumnInfo struct {
+	Name     string
+	Position int64
+	DataType string
+	Nullable bool
+	Comment  string
+}
+
+// ListTables returns the tables in catalog.schema ordered by name. With icebergOnly, only tables
+// whose data_source_format is ICEBERG are returned.
+func (c *DatabricksRESTClient) ListTables(ctx context.Context, catalog, schema string, icebergOnly bool) ([]TableInfo, error) {
+	query := `SELECT table_catalog, table_schema, table_name, table_type, data_source_format
+		FROM system.information_schema.tables
+		WHERE table_catalog = :catalog AND table_schema = :schema`
+	if icebergOnly {
+		query += ` AND data_source_format = 'ICEBERG'`
+	}
+	query += ` ORDER BY table_name`
+
+	resultSchema, rows, err := c.query(ctx, query,
+		StatementParameter{Name: "catalog", Value: catalog},
+		StatementParameter{Name: "schema", Value: schema})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables in %s.%s: %w", catalog, schema, err)
+	}
+
+	index := columnIndexes(resultSchema)
+	tables := make([]TableInfo, 0, len(rows))
+	for _, row := range rows {
+		r := namedRow{index: index, row: row}
+		tables = append(tables, TableInfo{
+			Catalog:          r.String("table_catalog"),
+			Schema:           r.String("table_schema"),
+			Name:             r.String("table_name"),
+			TableType:        r.String("table_type"),
+			DataSourceFormat: r.String("data_source_format"),
+		})
+	}
+	return tables, nil
+}
+
+// ListColumns returns the columns of catalog.schema.table in ordinal order
+func (c *DatabricksRESTClient) ListColumns(ctx context.Context, catalog, schema, table string) ([]ColumnInfo, error) {
+	query := `SELECT column_name, ordinal_position, data_type, is_nullable, comment
+		FROM system.information_schema.columns
+		WHERE table_catalog = :catalog AND table_schema = :schema AND table_name = :table
+		ORDER BY ordinal_position`
+
+	resultSchema, rows, err := c.query(ctx, query,
+		StatementParameter{Name: "catalog", Value: catalog},
+		StatementParameter{Name: "schema", Value: schema},
+		StatementParameter{Name: "table", Value: table})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list columns of %s.%s.%s: %w", catalog, schema, table, err)
+	}
+
+	index := columnIndexes(resultSchema)
+	columns := make([]ColumnInfo, 0, len(rows))
+	for _, row := range rows {
+		r := namedRow{index: index, row: row}
+		col := ColumnInfo{
+			Name:     r.String("column_name"),
+			DataType: r.String("data_type"),
+			Nullable: r.String("is_nullable") == "YES",
+			Comment:  r.String("comment"),
+		}
+		if col.Position, err = r.Int64("ordinal_position"); err != nil {
+			return nil, err
+		}
+		columns = append(columns, col)
+	}
+	return columns, nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// jsonSchemaDraft is the JSON Schema dialect JSONSchema emits
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// jsonSchemaProperty describes one column. DatabricksType keeps the full SQL type, so a retype
+// that maps to the same JSON type (INT to BIGINT) still changes the document.
+type jsonSchemaProperty struct {
+	Type           []string `json:"type"`
+	Format         string   `json:"format,omitempty"`
+	DatabricksType string   `json:"x-databricks-type"`
+	Position       int      `json:"x-position"`
+}
+
+type jsonSchemaDocument struct {
+	Schema               string                        `json:"$schema"`
+	Type                 string                        `json:"type"`
+	Properties           map[string]jsonSchemaProperty `json:"properties"`
+	Required             []string                      `json:"required"`
+	AdditionalProperties bool                          `json:"additionalProperties"`
+}
+
+// JSONSchema describes the result's rows, as written by WriteJSONL, as a JSON Schema object
+// with one property per manifest column. Every column is nullable and required. Map keys are
+// sorted, so the document is byte-for-byte stable for the same schema and can be diffed or
+// checked into CI.
+func (resp *StatementExecutionResponse) JSONSchema() ([]byte, error) {
+	doc := jsonSchemaDocument{
+		Schema:     jsonSchemaDraft,
+		Type:       "object",
+		Properties: make(map[string]jsonSchemaProperty, len(resp.Manifest.Schema.Columns)),
+		Required:   make([]string, 0, len(resp.Manifest.Schema.Columns)),
+	}
+	for i, col := range resp.Manifest.Schema.Columns {
+		jsonType, format := jsonSchemaType(col.TypeName)
+		doc.Properties[col.Name] = jsonSchemaProperty{
+			Type:           []string{jsonType, "null"},
+			Format:         format,
+			DatabricksType: columnType(col),
+			Position:       i,
+		}
+		doc.Required = append(doc.Required, col.Name)
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// jsonSchemaType maps a Databricks type name onto the JSON type convertValue decodes it to
+func jsonSchemaType(typeName string) (jsonType, format string) {
+	switch strings.ToUpper(typeName) {
+	case "LONG", "BIGINT", "INT", "INTEGER", "SHORT", "SMALLINT", "BYTE", "TINYINT":
+		return "integer", ""
+	case "DOUBLE", "FLOAT", "DECIMAL":
+		return "number", ""
+	case "BOOLEAN":
+		return "boolean", ""
+	case "DATE", "TIMESTAMP", "TIMESTAMP_NTZ":
+		// Both decode to time.Time, which marshals as RFC 3339
+		return "string", "date-time"
+	default:
+		// STRING, BINARY, INTERVAL and the complex types are passed through as their text form
+		return "string", ""
+	}
+}
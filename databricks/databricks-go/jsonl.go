@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// WriteJSONL writes each row of a materialized result as one JSON object per line, keyed by
+// column name in schema order, with values typed by the column's type_name. NULL cells are
+// written as JSON null.
+func WriteJSONL(resp *StatementExecutionResponse, w io.Writer) error {
+	rows, err := decodeRows(resp.Manifest.Schema, resp.Result.DataArray, DefaultTypeConverter)
+	if err != nil {
+		return fmt.Errorf("failed to decode rows: %w", err)
+	}
+
+	bw := bufio.NewWriter(w)
+	enc := newJSONLEncoder(resp.Manifest.Schema)
+	for _, row := range rows {
+		if err := enc.write(bw, row); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// StreamJSONL runs a statement and writes its rows to w as JSON lines, like WriteJSONL, while
+// holding only one chunk in memory at a time
+func (c *DatabricksRESTClient) StreamJSONL(ctx context.Context, statement string, w io.Writer) (*TimingInfo, error) {
+	bw := bufio.NewWriter(w)
+	var enc *jsonlEncoder
+	timing, err := c.streamRows(ctx, statement, func(schema Schema, row []any) error {
+		if enc == nil {
+			enc = newJSONLEncoder(schema)
+		}
+		return enc.write(bw, row)
+	})
+	if err != nil {
+		return timing, err
+	}
+	return timing, bw.Flush()
+}
+
+// jsonlEncoder renders rows as JSON objects whose keys follow the schema's column order
+type jsonlEncoder struct {
+	keys [][]byte
+	buf  bytes.Buffer
+}
+
+func newJSONLEncoder(schema Schema) *jsonlEncoder {
+	keys := make([][]byte, len(schema.Columns))
+	for i, col := range schema.Columns {
+		// Marshaling a string cannot fail
+		keys[i], _ = json.Marshal(col.Name)
+	}
+	return &jsonlEncoder{keys: keys}
+}
+
+func (e *jsonlEncoder) write(w io.Writer, row []any) error {
+	e.buf.Reset()
+	e.buf.WriteByte('{')
+	for i, value := range row {
+		if i > 0 {
+			e.buf.WriteByte(',')
+		}
+		if i < len(e.keys) {
+			e.buf.Write(e.keys[i])
+		} else {
+			fmt.Fprintf(&e.buf, `"col_%d"`, i)
+		}
+		e.buf.WriteByte(':')
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to encode column %d: %w", i, err)
+		}
+		e.buf.Write(encoded)
+	}
+	e.buf.WriteString("}\n")
+	_, err := w.Write(e.buf.Bytes())
+	return err
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"math"
+	"slices"
+	"time"
+)
+
+// defaultSketchRelativeError is the percentile accuracy of a latencySketch when none is given
+const defaultSketchRelativeError = 0.01
+
+// latencySketch estimates percentiles in bounded memory by counting durations in logarithmic
+// buckets, as HDR histograms do. Every reported percentile is within alpha of the true sample's
+// value relative to that value, and the bucket count grows only with the log of the range of
+// durations seen, not with the number of samples.
+type latencySketch struct {
+	alpha    float64
+	gamma    float64
+	logGamma float64
+
+	// counts is keyed by bucket index; bucket i holds durations in (gamma^(i-1), gamma^i] ns
+	counts map[int]int64
+	// zeros counts zero durations, which have no logarithm
+	zeros int64
+
+	n        int64
+	sum      time.Duration
+	min, max time.Duration
+}
+
+func newLatencySketch(alpha float64) *latencySketch {
+	if alpha <= 0 || alpha >= 1 {
+		alpha = defaultSketchRelativeError
+	}
+	gamma := (1 + alpha) / (1 - alpha)
+	return &latencySketch{
+		alpha:    alpha,
+		gamma:    gamma,
+		logGamma: math.Log(gamma),
+		counts:   map[int]int64{},
+	}
+}
+
+func (s *latencySketch) add(d time.Duration) {
+	if s.n == 0 || d < s.min {
+		s.min = d
+	}
+	if s.n == 0 || d > s.max {
+		s.max = d
+	}
+	s.n++
+	s.sum += d
+
+	if d <= 0 {
+		s.zeros++
+		return
+	}
+	s.counts[int(math.Ceil(math.Log(float64(d))/s.logGamma))]++
+}
+
+// quantile returns the p-th percentile by the same nearest-rank rule as Summarize, as the
+// midpoint of the bucket holding that rank, clamped to the observed min and max
+func (s *latencySketch) quantile(p float64) time.Duration {
+	if s.n == 0 {
+		return 0
+	}
+	rank := max(int64(math.Ceil(p/100*float64(s.n))), 1)
+	if rank <= s.zeros {
+		return s.min
+	}
+
+	seen := s.zeros
+	buckets := make([]int, 0, len(s.counts))
+	for i := range s.counts {
+		buckets = append(buckets, i)
+	}
+	slices.Sort(buckets)
+	for _, i := range buckets {
+		seen += s.counts[i]
+		if seen >= rank {
+			// 2*gamma^i/(gamma+1) is within alpha of every value in (gamma^(i-1), gamma^i]
+			value := time.Duration(2 * math.Pow(s.gamma, float64(i)) / (s.gamma + 1))
+			return min(max(value, s.min), s.max)
+		}
+	}
+	return s.max
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"math"
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"time"
+)
+
+// sketchQuantiles are the percentiles checked against the exact nearest-rank values
+var sketchQuantiles = []float64{0.1, 1, 10, 25, 50, 75, 90, 95, 99, 99.9, 100}
+
+func TestLatencySketchRelativeError(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	distributions := map[string]func() time.Duration{
+		"uniform 1ms-10s": func() time.Duration {
+			return time.Millisecond + time.Duration(rng.Int64N(int64(10*time.Second)))
+		},
+		"exponential mean 200ms": func() time.Duration {
+			return time.Duration(rng.ExpFloat64() * float64(200*time.Millisecond))
+		},
+		"lognormal": func() time.Duration {
+			return time.Duration(math.Exp(rng.NormFloat64()*1.5) * float64(100*time.Millisecond))
+		},
+		"bimodal cache hits and misses": func() time.Duration {
+			if rng.IntN(10) < 7 {
+				return time.Duration(5+rng.IntN(10)) * time.Millisecond
+			}
+			return time.Duration(2000+rng.IntN(30000)) * time.Millisecond
+		},
+		"whole milliseconds with zeros": func() time.Duration {
+			return time.Duration(rng.IntN(50)) * time.Millisecond
+		},
+	}
+
+	for name, next := range distributions {
+		for _, alpha := range []float64{0.01, 0.05} {
+			sketch := newLatencySketch(alpha)
+			samples := make([]time.Duration, 100000)
+			for i := range samples {
+				samples[i] = next()
+				sketch.add(samples[i])
+			}
+			slices.Sort(samples)
+
+			for _, p := range sketchQuantiles {
+				exact := nearestRank(samples, p)
+				got := sketch.quantile(p)
+				// Allow for float rounding at bucket boundaries on top of alpha
+				if diff := math.Abs(float64(got - exact)); diff > alpha*float64(exact)*(1+1e-9) {
+					t.Errorf("%s, alpha %g: p%g = %s, exact %s, error %.4f > %g",
+						name, alpha, p, got, exact, diff/float64(exact), alpha)
+				}
+			}
+			if sketch.min != samples[0] || sketch.max != samples[len(samples)-1] {
+				t.Errorf("%s: min/max = %s/%s, want %s/%s", name, sketch.min, sketch.max, samples[0], samples[len(samples)-1])
+			}
+		}
+	}
+}
+
+func TestLatencySketchBucketsBounded(t *testing.T) {
+	const alpha = 0.01
+	sketch := newLatencySketch(alpha)
+	rng := rand.New(rand.NewPCG(3, 4))
+
+	// Durations from 1µs to 1h span about 9.6 decades; each decade needs ln(10)/ln(gamma) buckets
+	lo, hi := time.Microsecond, time.Hour
+	gamma := (1 + alpha) / (1 - alpha)
+	bound := int(math.Ceil(math.Log(float64(hi)/float64(lo))/math.Log(gamma))) + 1
+
+	var bucketsAt []int
+	for _, n := range []int{10_000, 100_000, 1_000_000} {
+		for sketch.n < int64(n) {
+			// Log-uniform over the range, the worst case for bucket count
+			d := time.Duration(float64(lo) * math.Pow(float64(hi)/float64(lo), rng.Float64()))
+			sketch.add(d)
+		}
+		if len(sketch.counts) > bound {
+			t.Fatalf("%d samples use %d buckets, want at most %d", n, len(sketch.counts), bound)
+		}
+		bucketsAt = append(bucketsAt, len(sketch.counts))
+	}
+	// Ten times more samples over the same range must not need meaningfully more buckets
+	if bucketsAt[2] > bucketsAt[1]+bucketsAt[1]/100 {
+		t.Errorf("bucket count kept growing with samples: %v", bucketsAt)
+	}
+}
+
+func TestStreamingStatsMatchExact(t *testing.T) {
+	rng := rand.New(rand.NewPCG(5, 6))
+	infos := make([]TimingInfo, 50000)
+	for i := range infos {
+		infos[i] = TimingInfo{DurationMs: int64(1 + rng.ExpFloat64()*300)}
+	}
+	infos[10].ErrorMessage = "failed"
+
+	exact := Summarize(infos)
+	acc := timingAccumulator{streamAfter: 1000, relativeError: 0.02}
+	for _, info := range infos {
+		acc.add(info)
+	}
+	streamed := acc.stats()
+
+	if streamed.RelativeError != 0.02 || exact.RelativeError != 0 {
+		t.Errorf("relative error = %g (exact %g), want 0.02 (0)", streamed.RelativeError, exact.RelativeError)
+	}
+	if streamed.N != exact.N || streamed.Failures != 1 || streamed.Min != exact.Min || streamed.Max != exact.Max || streamed.Mean != exact.Mean {
+		t.Errorf("streamed = %+v, exact = %+v; N, failures, min, max and mean must match exactly", streamed, exact)
+	}
+	for _, pair := range [][2]time.Duration{{streamed.P50, exact.P50}, {streamed.P90, exact.P90}, {streamed.P99, exact.P99}} {
+		if diff := math.Abs(float64(pair[0] - pair[1])); diff > streamed.RelativeError*float64(pair[1])*(1+1e-9) {
+			t.Errorf("streamed percentile %s vs exact %s exceeds %g relative error", pair[0], pair[1], streamed.RelativeError)
+		}
+	}
+	if acc.durations != nil {
+		t.Errorf("%d exact samples kept after switching to streaming", len(acc.durations))
+	}
+}
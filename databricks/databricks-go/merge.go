@@ -0,0 +1,158 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Default aliases MergeSpec uses for the target and source tables
+const (
+	defaultMergeTargetAlias = "t"
+	defaultMergeSourceAlias = "s"
+)
+
+// MergeClause is one WHEN branch of a MERGE. Action is the SQL after THEN, e.g. "UPDATE SET *",
+// "UPDATE SET t.qty = s.qty", "DELETE" or "INSERT *". Condition, when set, is ANDed onto the
+// branch.
+type MergeClause struct {
+	Condition string
+	Action    string
+}
+
+// MergeSpec describes a MERGE INTO statement. Target and Source are catalog.schema.table names;
+// On, the clause conditions and actions are SQL written against TargetAlias and SourceAlias,
+// which default to t and s.
+type MergeSpec struct {
+	Target         string
+	TargetAlias    string
+	Source         string
+	SourceAlias    string
+	On             string
+	WhenMatched    []MergeClause
+	WhenNotMatched []MergeClause
+}
+
+// MergeResult is the row breakdown reported by MERGE INTO, with the statement ID for pulling
+// detailed history afterward
+type MergeResult struct {
+	StatementID     string
+	NumAffectedRows int64
+	NumUpdatedRows  int64
+	NumDeletedRows  int64
+	NumInsertedRows int64
+}
+
+// Merge builds and runs a MERGE INTO from spec and returns its row breakdown. A spec whose ON
+// clause never references the target alias is rejected, since such a condition matches every
+// target row against every source row and rewrites the whole table.
+func (c *DatabricksRESTClient) Merge(ctx context.Context, spec MergeSpec) (MergeResult, error) {
+	stmt, err := spec.sql()
+	if err != nil {
+		return MergeResult{}, err
+	}
+
+	resp, _, err := c.ExecuteStatement(ctx, stmt)
+	if err != nil {
+		return MergeResult{}, fmt.Errorf("failed to merge into %s: %w", spec.Target, err)
+	}
+	result := MergeResult{StatementID: resp.StatementID}
+
+	rows, err := decodeRows(resp.Manifest.Schema, resp.Result.DataArray, c.typeConverter())
+	if err != nil {
+		return result, fmt.Errorf("failed to decode merge metrics for %s: %w", spec.Target, err)
+	}
+	if len(rows) == 0 {
+		return result, fmt.Errorf("merge into %s returned no metrics", spec.Target)
+	}
+	r := namedRow{index: columnIndexes(resp.Manifest.Schema), row: rows[0]}
+	if result.NumAffectedRows, err = r.Int64("num_affected_rows"); err != nil {
+		return result, err
+	}
+	if result.NumUpdatedRows, err = r.Int64("num_updated_rows"); err != nil {
+		return result, err
+	}
+	if result.NumDeletedRows, err = r.Int64("num_deleted_rows"); err != nil {
+		return result, err
+	}
+	if result.NumInsertedRows, err = r.Int64("num_inserted_rows"); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// sql validates the spec and renders the MERGE statement
+func (spec MergeSpec) sql() (string, error) {
+	if err := validateTableName(spec.Target); err != nil {
+		return "", fmt.Errorf("merge target: %w", err)
+	}
+	if err := validateTableName(spec.Source); err != nil {
+		return "", fmt.Errorf("merge source: %w", err)
+	}
+	targetAlias := cmp.Or(spec.TargetAlias, defaultMergeTargetAlias)
+	sourceAlias := cmp.Or(spec.SourceAlias, defaultMergeSourceAlias)
+	for _, alias := range []string{targetAlias, sourceAlias} {
+		if !identifierPart.MatchString(alias) || strings.HasPrefix(alias, "`") {
+			return "", fmt.Errorf("merge: invalid alias %q", alias)
+		}
+	}
+	if strings.EqualFold(targetAlias, sourceAlias) {
+		return "", fmt.Errorf("merge: target and source share the alias %q", targetAlias)
+	}
+
+	if strings.TrimSpace(spec.On) == "" {
+		return "", errors.New("merge: ON condition is empty")
+	}
+	if !referencesAlias(spec.On, targetAlias) {
+		return "", fmt.Errorf("merge: ON condition %q references no %s.* target column and would rewrite all of %s", spec.On, targetAlias, spec.Target)
+	}
+	if len(spec.WhenMatched) == 0 && len(spec.WhenNotMatched) == 0 {
+		return "", errors.New("merge: no WHEN MATCHED or WHEN NOT MATCHED clause")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "MERGE INTO %s AS %s USING %s AS %s ON %s", spec.Target, targetAlias, spec.Source, sourceAlias, spec.On)
+	for _, clause := range spec.WhenMatched {
+		if err := clause.writeTo(&b, "WHEN MATCHED", "UPDATE", "DELETE"); err != nil {
+			return "", err
+		}
+	}
+	for _, clause := range spec.WhenNotMatched {
+		if err := clause.writeTo(&b, "WHEN NOT MATCHED", "INSERT"); err != nil {
+			return "", err
+		}
+	}
+	return b.String(), nil
+}
+
+// writeTo appends the clause after checking its action starts with one of the allowed keywords
+func (clause MergeClause) writeTo(b *strings.Builder, when string, allowed ...string) error {
+	action := strings.TrimSpace(clause.Action)
+	upper := strings.ToUpper(action)
+	ok := false
+	for _, keyword := range allowed {
+		if upper == keyword || strings.HasPrefix(upper, keyword+" ") {
+			ok = true
+			break
+		}
+	}
+	if !ok {
+		return fmt.Errorf("merge: %s action %q must start with %s", when, clause.Action, strings.Join(allowed, " or "))
+	}
+
+	b.WriteString(" " + when)
+	if condition := strings.TrimSpace(clause.Condition); condition != "" {
+		b.WriteString(" AND " + condition)
+	}
+	b.WriteString(" THEN " + action)
+	return nil
+}
+
+// referencesAlias reports whether expr contains a column qualified by alias, e.g. t.id or t.`id`
+func referencesAlias(expr, alias string) bool {
+	pattern := regexp.MustCompile(`(?i)(?:^|[^A-Za-z0-9_.` + "`" + `])` + regexp.QuoteMeta(alias) + `\s*\.`)
+	return pattern.MatchString(expr)
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// MarshalNative serializes the response in the Statement Execution API's own statement_id,
+// status, manifest and result shape. The result is written as a single inline chunk: rows
+// assembled from every chunk, as ExecuteStatement returns them, go into result.data_array and the
+// manifest is rewritten to describe one chunk, so the document can be cached and decoded later by
+// anything that reads the raw API format. A result that still links to chunks it has not fetched
+// is an error rather than a silently truncated document. A result the server truncated keeps its
+// truncated flag and original total_row_count, so the document still reads as partial.
+func (resp *StatementExecutionResponse) MarshalNative() ([]byte, error) {
+	if len(resp.Result.ExternalLinks) > 0 {
+		return nil, fmt.Errorf("statement %s: result uses external links and has not been materialized", resp.StatementID)
+	}
+	if resp.Result.nextLink() != "" {
+		return nil, fmt.Errorf("statement %s: result has unfetched chunks after chunk %d", resp.StatementID, resp.Result.ChunkIndex)
+	}
+
+	rowCount := int64(len(resp.Result.DataArray))
+	native := *resp
+	native.Manifest.TotalRowCount = max(resp.Manifest.TotalRowCount, rowCount)
+	native.Manifest.TotalChunkCount = 0
+	native.Manifest.Chunks = nil
+	// The chunk list is what tells the rows returned apart from total_row_count, so a truncated
+	// result keeps one even when it returned no rows
+	if rowCount > 0 || native.Manifest.TotalRowCount > rowCount {
+		native.Manifest.TotalChunkCount = 1
+		native.Manifest.Chunks = []ChunkInfo{{RowCount: rowCount}}
+	}
+	native.Result = ResultData{
+		RowCount:  rowCount,
+		DataArray: resp.Result.DataArray,
+	}
+
+	data, err := json.Marshal(native)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode statement %s: %w", resp.StatementID, err)
+	}
+	return data, nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"databricks-go-timing-test/testserver"
+)
+
+func TestMarshalNativeAssemblesChunks(t *testing.T) {
+	client, handler := newTestClient(t)
+	handler.AddStatement(testserver.Statement{Text: "SELECT n FROM range(7)", Rows: testserver.IntRows(7), ChunkSize: 3})
+
+	resp, _, err := client.ExecuteStatement(context.Background(), "SELECT n FROM range(7)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := resp.MarshalNative()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded StatementExecutionResponse
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	m := decoded.Manifest
+	if m.TotalRowCount != 7 || m.TotalChunkCount != 1 || len(m.Chunks) != 1 || m.Truncated {
+		t.Errorf("manifest = %+v, want one complete chunk of 7 rows", m)
+	}
+	if len(decoded.Result.DataArray) != 7 || decoded.Result.nextLink() != "" {
+		t.Errorf("result = %+v, want 7 inline rows and no next chunk", decoded.Result)
+	}
+	if decoded.returnedRowCount() != 7 {
+		t.Errorf("returned rows = %d, want 7", decoded.returnedRowCount())
+	}
+}
+
+func TestMarshalNativeKeepsTruncation(t *testing.T) {
+	one := "1"
+	tests := []struct {
+		name string
+		resp StatementExecutionResponse
+		rows int64
+	}{
+		{
+			name: "byte limit",
+			resp: StatementExecutionResponse{
+				Manifest: Manifest{TotalRowCount: 1000, Truncated: true, TotalChunkCount: 1, Chunks: []ChunkInfo{{RowCount: 1}}},
+				Result:   ResultData{RowCount: 1, DataArray: [][]*string{{&one}}},
+			},
+			rows: 1,
+		},
+		{
+			name: "no rows returned",
+			resp: StatementExecutionResponse{
+				Manifest: Manifest{TotalRowCount: 1000, Truncated: true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.resp.StatementID = "01f0-0001"
+			data, err := tt.resp.MarshalNative()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var decoded StatementExecutionResponse
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatal(err)
+			}
+			if !decoded.Manifest.Truncated || decoded.Manifest.TotalRowCount != 1000 {
+				t.Errorf("manifest = %+v, want it still truncated with 1000 total rows", decoded.Manifest)
+			}
+			if got := decoded.returnedRowCount(); got != tt.rows {
+				t.Errorf("returned rows = %d, want %d", got, tt.rows)
+			}
+		})
+	}
+}
+
+func TestMarshalNativeUnfetchedChunks(t *testing.T) {
+	next := 1
+	resp := StatementExecutionResponse{
+		StatementID: "01f0-0001",
+		Result:      ResultData{NextChunkIndex: &next, NextChunkInternalLink: "/api/2.0/sql/statements/01f0-0001/result/chunks/1"},
+	}
+	if _, err := resp.MarshalNative(); err == nil {
+		t.Error("a result with unfetched chunks was marshaled")
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// oauthRefreshWindow is how long before expiry a cached access token is refreshed
+const oauthRefreshWindow = 60 * time.Second
+
+// OAuthProvider is a CredentialProvider for a service principal using the OAuth
+// machine-to-machine client-credentials grant. Access tokens are cached and refreshed
+// automatically when they are within 60 seconds of expiry. Tokens are requested from the
+// workspace of the client the provider is installed on, through that client's HTTP settings.
+type OAuthProvider struct {
+	clientID     string
+	clientSecret string
+	// client is the REST client whose workspace issues the tokens
+	client *DatabricksRESTClient
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+// NewOAuthProvider returns an OAuthProvider for the given service principal, for use with
+// WithCredentialProvider
+func NewOAuthProvider(clientID, clientSecret string) *OAuthProvider {
+	return &OAuthProvider{clientID: clientID, clientSecret: clientSecret}
+}
+
+// oauthTokenResponse is the response from the workspace /oidc/v1/token endpoint
+type oauthTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// NewDatabricksRESTClientOAuth creates a REST client that authenticates as a service principal
+// through an OAuthProvider
+func NewDatabricksRESTClientOAuth(hostname, clientID, clientSecret, warehouseID string, opts ...ClientOption) *DatabricksRESTClient {
+	opts = append(opts, WithCredentialProvider(NewOAuthProvider(clientID, clientSecret)))
+	return NewDatabricksRESTClient(hostname, "", warehouseID, opts...)
+}
+
+// Token returns a valid access token, requesting a new one if the cached token is missing or
+// about to expire
+func (p *OAuthProvider) Token(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.accessToken != "" && time.Until(p.expiresAt) > oauthRefreshWindow {
+		return p.accessToken, nil
+	}
+	return p.refreshLocked(ctx)
+}
+
+// refresh requests a new access token even if the cached one is still valid
+func (p *OAuthProvider) refresh(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, err := p.refreshLocked(ctx)
+	return err
+}
+
+func (p *OAuthProvider) refreshLocked(ctx context.Context) (string, error) {
+	if p.client == nil {
+		return "", fmt.Errorf("OAuth provider for %s is not installed on a client", p.clientID)
+	}
+	token, err := p.requestToken(ctx)
+	if err != nil {
+		return "", err
+	}
+	p.accessToken = token.AccessToken
+	p.expiresAt = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	return p.accessToken, nil
+}
+
+// expiry returns when the cached access token expires
+func (p *OAuthProvider) expiry() time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.expiresAt
+}
+
+// requestToken performs the client-credentials grant against the workspace token endpoint
+func (p *OAuthProvider) requestToken(ctx context.Context) (*oauthTokenResponse, error) {
+	form := url.Values{
+		"grant_type": {"client_credentials"},
+		"scope":      {"all-apis"},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", "https://"+p.client.hostname+"/oidc/v1/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.SetBasicAuth(p.clientID, p.clientSecret)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.client.doHTTP(req)
+	if err != nil {
+		return nil, fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token request failed: %w", newAPIError(resp.StatusCode, body))
+	}
+
+	var token oauthTokenResponse
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, fmt.Errorf("failed to parse token response: %w", err)
+	}
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("token response did not include an access_token")
+	}
+	return &token, nil
+}
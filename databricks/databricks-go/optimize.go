@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// OptimizeResult is the file-compaction summary reported by OPTIMIZE
+type OptimizeResult struct {
+	NumFilesAdded   int64
+	NumFilesRemoved int64
+	NumBytesAdded   int64
+	NumBytesRemoved int64
+}
+
+// optimizeMetrics is the subset of the OPTIMIZE metrics struct that OptimizeResult reports
+type optimizeMetrics struct {
+	NumFilesAdded   int64 `json:"numFilesAdded"`
+	NumFilesRemoved int64 `json:"numFilesRemoved"`
+	FilesAdded      struct {
+		TotalSize int64 `json:"totalSize"`
+	} `json:"filesAdded"`
+	FilesRemoved struct {
+		TotalSize int64 `json:"totalSize"`
+	} `json:"filesRemoved"`
+}
+
+// OptimizeTable compacts a catalog.schema.table, z-ordering by the given columns when any are
+// provided, and returns the resulting file metrics
+func (c *DatabricksRESTClient) OptimizeTable(ctx context.Context, fullTableName string, zorderBy []string) (OptimizeResult, error) {
+	if err := validateTableName(fullTableName); err != nil {
+		return OptimizeResult{}, err
+	}
+
+	stmt := "OPTIMIZE " + fullTableName
+	if len(zorderBy) > 0 {
+		cols := make([]string, len(zorderBy))
+		for i, col := range zorderBy {
+			quoted, err := quoteIdentifier(col)
+			if err != nil {
+				return OptimizeResult{}, fmt.Errorf("invalid ZORDER BY column %d: %w", i, err)
+			}
+			cols[i] = quoted
+		}
+		stmt += " ZORDER BY (" + strings.Join(cols, ", ") + ")"
+	}
+
+	resp, _, err := c.ExecuteStatement(ctx, stmt)
+	if err != nil {
+		return OptimizeResult{}, fmt.Errorf("failed to optimize %s: %w", fullTableName, err)
+	}
+	col, ok := resp.Manifest.Schema.ColumnIndex("metrics")
+	if !ok || len(resp.Result.DataArray) == 0 {
+		return OptimizeResult{}, fmt.Errorf("optimize of %s returned no metrics", fullTableName)
+	}
+	row := resp.Result.DataArray[0]
+	if col >= len(row) || row[col] == nil {
+		return OptimizeResult{}, fmt.Errorf("optimize of %s returned no metrics", fullTableName)
+	}
+
+	var m optimizeMetrics
+	if err := json.Unmarshal([]byte(*row[col]), &m); err != nil {
+		return OptimizeResult{}, fmt.Errorf("failed to parse optimize metrics for %s: %w", fullTableName, err)
+	}
+	return OptimizeResult{
+		NumFilesAdded:   m.NumFilesAdded,
+		NumFilesRemoved: m.NumFilesRemoved,
+		NumBytesAdded:   m.FilesAdded.TotalSize,
+		NumBytesRemoved: m.FilesRemoved.TotalSize,
+	}, nil
+}
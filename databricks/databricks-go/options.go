@@ -0,0 +1,112 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+
+	"golang.org/x/time/rate"
+)
+
+// ClientOption configures optional behavior of a DatabricksRESTClient
+type ClientOption func(*DatabricksRESTClient)
+
+// WithCredentialProvider authenticates every request with a token from provider instead of the
+// token passed to the constructor. An *OAuthProvider requests its tokens through this client.
+func WithCredentialProvider(provider CredentialProvider) ClientOption {
+	return func(c *DatabricksRESTClient) {
+		if p, ok := provider.(*OAuthProvider); ok && p.client == nil {
+			p.client = c
+		}
+		c.credentials = provider
+	}
+}
+
+// WithRateLimit throttles outgoing requests to rps requests per second with bursts of up to
+// burst requests. Waiting for a token respects the request context. By default the client is
+// not rate limited; a non-positive rps keeps it unlimited, and a burst below 1 is raised to 1 so
+// that requests are paced rather than blocked outright.
+func WithRateLimit(rps float64, burst int) ClientOption {
+	return func(c *DatabricksRESTClient) {
+		if rps <= 0 {
+			c.limiter = nil
+			return
+		}
+		c.limiter = rate.NewLimiter(rate.Limit(rps), max(burst, 1))
+	}
+}
+
+// WithHTTPClient replaces the HTTP client used for every request. By default the client uses an
+// http.Client with a 60-second timeout, which covers the API's 50-second wait_timeout; a nil
+// client keeps that default. For tests, pass the Client() of an httptest.NewTLSServer and use
+// the server's host as hostname.
+func WithHTTPClient(client *http.Client) ClientOption {
+	return func(c *DatabricksRESTClient) {
+		if client == nil {
+			client = newDefaultHTTPClient()
+		}
+		c.httpClient = client
+	}
+}
+
+// WithTransport keeps the default 60-second timeout but sends requests through rt, e.g. to log
+// requests, inject headers, or serve canned responses in tests. The client given to an earlier
+// WithHTTPClient is copied rather than modified.
+func WithTransport(rt http.RoundTripper) ClientOption {
+	return func(c *DatabricksRESTClient) {
+		c.httpClient = c.httpClientWithTransport(rt)
+	}
+}
+
+// httpClientWithTransport returns a copy of the client's HTTP client that sends through rt,
+// starting from the default client when none is set
+func (c *DatabricksRESTClient) httpClientWithTransport(rt http.RoundTripper) *http.Client {
+	client := newDefaultHTTPClient()
+	if c.httpClient != nil {
+		*client = *c.httpClient
+	}
+	client.Transport = rt
+	return client
+}
+
+// WithCache keeps up to size history lookups from GetHistoryQuery and QueryHistoryByID in an
+// in-memory LRU cache keyed by statement ID. Only finished statements are cached, since their
+// history never changes. A non-positive size leaves caching disabled.
+func WithCache(size int) ClientOption {
+	return func(c *DatabricksRESTClient) {
+		if size > 0 {
+			c.cache = newLRUCache(size)
+		}
+	}
+}
+
+// WithNamespace makes catalog.schema the default for resolving unqualified table names in every
+// statement the client runs. ExecOptions.Catalog and Schema override it per statement.
+func WithNamespace(catalog, schema string) ClientOption {
+	return func(c *DatabricksRESTClient) {
+		c.catalog = catalog
+		c.schema = schema
+	}
+}
+
+// WithLogger sends the client's structured debug logs (statement IDs, states, durations and
+// retries) to logger. By default the client logs nothing.
+func WithLogger(logger *slog.Logger) ClientOption {
+	return func(c *DatabricksRESTClient) {
+		if logger != nil {
+			c.logger = logger
+		}
+	}
+}
+
+// WithRetryBudget shares a retry budget across every request of the client: each successful
+// request earns ratio retries, e.g. 0.1 allows one retry per ten successes, up to a small
+// reserve. Once the budget is spent, failing requests return their error immediately instead of
+// retrying, so a whole batch can't turn an outage into a retry storm. A non-positive ratio leaves
+// retries limited only by MaxRetries.
+func WithRetryBudget(ratio float64) ClientOption {
+	return func(c *DatabricksRESTClient) {
+		if ratio > 0 {
+			c.retryBudget = newRetryBudget(ratio)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"databricks-go-timing-test/testserver"
+)
+
+// roundTripFunc adapts a function to http.RoundTripper
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// cannedTransport answers every request with body and records the requests it saw
+func cannedTransport(body string, seen *[]*http.Request) http.RoundTripper {
+	return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		*seen = append(*seen, req)
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}, nil
+	})
+}
+
+func TestWithTransport(t *testing.T) {
+	var seen []*http.Request
+	client := NewDatabricksRESTClient("example.cloud.databricks.com", "token", "warehouse",
+		WithTransport(cannedTransport(`{"statement_id":"01f0-0001","status":{"state":"SUCCEEDED"},"manifest":{"total_row_count":1}}`, &seen)))
+
+	timing, err := client.ExecuteStatementWithREST(context.Background(), "SELECT 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if timing.QueryID != "01f0-0001" || timing.RowCount != 1 {
+		t.Errorf("timing = %+v", timing)
+	}
+	if len(seen) != 1 || seen[0].URL.String() != "https://example.cloud.databricks.com/api/2.0/sql/statements" {
+		t.Fatalf("transport saw %v", seen)
+	}
+	if got := seen[0].Header.Get("Authorization"); got != "Bearer token" {
+		t.Errorf("Authorization = %q", got)
+	}
+	if client.httpClient.Timeout != defaultHTTPTimeout {
+		t.Errorf("timeout = %s, want the default %s", client.httpClient.Timeout, defaultHTTPTimeout)
+	}
+}
+
+func TestWithTransportCopiesHTTPClient(t *testing.T) {
+	base := &http.Client{Timeout: 5 * time.Second}
+	var seen []*http.Request
+	client := NewDatabricksRESTClient("example.cloud.databricks.com", "token", "warehouse",
+		WithHTTPClient(base), WithTransport(cannedTransport(`{}`, &seen)))
+
+	if client.httpClient == base || base.Transport != nil {
+		t.Error("WithTransport modified the client passed to WithHTTPClient")
+	}
+	if client.httpClient.Timeout != 5*time.Second {
+		t.Errorf("timeout = %s, want the 5s from WithHTTPClient", client.httpClient.Timeout)
+	}
+}
+
+func TestNilHTTPClient(t *testing.T) {
+	var seen []*http.Request
+	for name, opts := range map[string][]ClientOption{
+		"WithHTTPClient(nil)":                []ClientOption{WithHTTPClient(nil)},
+		"WithHTTPClient(nil), WithTransport": []ClientOption{WithHTTPClient(nil), WithTransport(cannedTransport(`{}`, &seen))},
+		"WithHTTPClient(nil), WithRecorder":  []ClientOption{WithHTTPClient(nil), WithRecorder(t.TempDir())},
+		"WithHTTPClient(nil), WithReplayer":  []ClientOption{WithHTTPClient(nil), WithReplayer(t.TempDir())},
+	} {
+		client := NewDatabricksRESTClient("example.cloud.databricks.com", "token", "warehouse", opts...)
+		if client.httpClient == nil || client.httpClient.Timeout != defaultHTTPTimeout {
+			t.Errorf("%s: HTTP client = %+v, want the default", name, client.httpClient)
+		}
+	}
+}
+
+func TestWithRateLimit(t *testing.T) {
+	tests := []struct {
+		name      string
+		rps       float64
+		burst     int
+		limited   bool
+		wantBurst int
+	}{
+		{name: "limited", rps: 10, burst: 5, limited: true, wantBurst: 5},
+		{name: "zero burst", rps: 10, burst: 0, limited: true, wantBurst: 1},
+		{name: "negative burst", rps: 10, burst: -3, limited: true, wantBurst: 1},
+		{name: "zero rps", rps: 0, burst: 5},
+		{name: "negative rps", rps: -1, burst: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewDatabricksRESTClient("example.cloud.databricks.com", "token", "warehouse", WithRateLimit(tt.rps, tt.burst))
+			if (client.limiter != nil) != tt.limited {
+				t.Fatalf("limiter = %v, want limited %v", client.limiter, tt.limited)
+			}
+			if tt.limited && client.limiter.Burst() != tt.wantBurst {
+				t.Errorf("burst = %d, want %d", client.limiter.Burst(), tt.wantBurst)
+			}
+		})
+	}
+}
+
+func TestWithRateLimitZeroBurstDoesNotBlock(t *testing.T) {
+	client, handler := newTestClient(t, WithRateLimit(100, 0))
+	handler.AddStatement(testserver.Statement{Text: "SELECT 1", Rows: testserver.IntRows(1)})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	for range 3 {
+		if _, err := client.ExecuteStatementWithREST(ctx, "SELECT 1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
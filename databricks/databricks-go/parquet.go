@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/parquet"
+	"github.com/apache/arrow/go/v12/parquet/pqarrow"
+)
+
+// OrderAllColumns in ExportOptions.EnsureOrder orders by every result column, for results
+// without a unique key
+const OrderAllColumns = "*"
+
+// largeOrderedExportRows is the row count above which an ordered export logs that it forced a
+// sort of the whole result
+const largeOrderedExportRows = 1_000_000
+
+// ExportOptions controls how ExecuteToParquetWithOptions produces its file
+type ExportOptions struct {
+	// EnsureOrder appends ORDER BY on these columns so repeated exports of unchanged data write
+	// identical files; Databricks does not otherwise guarantee row order. The columns should form
+	// a unique key, or ties may still come back in any order. OrderAllColumns orders by every
+	// column. Ordering forces a sort of the whole result on the warehouse.
+	EnsureOrder []string
+}
+
+// ExecuteToParquet runs a statement with the ARROW_STREAM format and writes every record batch,
+// across all chunks, to w as a single Parquet file. The Parquet schema is the result's Arrow
+// schema, so column names and types match the manifest. A result with no rows is written as an
+// empty file with the manifest's columns. Batches are written as they are downloaded, so the
+// whole result is never held in memory.
+func (c *DatabricksRESTClient) ExecuteToParquet(ctx context.Context, statement string, w io.Writer) (*TimingInfo, error) {
+	return c.ExecuteToParquetWithOptions(ctx, statement, w, ExportOptions{})
+}
+
+// ExecuteToParquetWithOptions is ExecuteToParquet with the statement's rows ordered per opts
+func (c *DatabricksRESTClient) ExecuteToParquetWithOptions(ctx context.Context, statement string, w io.Writer, opts ExportOptions) (*TimingInfo, error) {
+	if len(opts.EnsureOrder) > 0 {
+		ordered, err := orderedStatement(statement, opts.EnsureOrder)
+		if err != nil {
+			return nil, err
+		}
+		statement = ordered
+	}
+
+	reqBody := c.newStatementRequest(statement)
+	// ARROW_STREAM is only served through presigned links, never inline
+	reqBody.Disposition = DispositionExternalLinks
+	reqBody.Format = FormatArrowStream
+
+	timing, resp, err := c.executeStatement(ctx, reqBody)
+	if err != nil {
+		return timing, err
+	}
+
+	if len(opts.EnsureOrder) > 0 && resp.Manifest.TotalRowCount > largeOrderedExportRows {
+		c.logger.WarnContext(ctx, "ordered export sorted a large result",
+			slog.String("statement_id", resp.StatementID),
+			slog.Int64("rows", resp.Manifest.TotalRowCount),
+			slog.String("order_by", strings.Join(opts.EnsureOrder, ", ")))
+	}
+
+	ctx, transfer := withTransferStats(ctx)
+	defer transfer.applyTo(timing)
+
+	// The writer is created from the first batch's schema, which carries the exact Arrow types
+	var writer *pqarrow.FileWriter
+	err = c.forEachArrowBatch(ctx, resp, func(record arrow.Record) error {
+		if writer == nil {
+			var err error
+			if writer, err = newParquetWriter(record.Schema(), w); err != nil {
+				return err
+			}
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write Parquet row group: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		if writer != nil {
+			writer.Close()
+		}
+		return timing, err
+	}
+
+	if writer == nil {
+		if writer, err = newParquetWriter(arrowSchemaFromManifest(resp.Manifest.Schema), w); err != nil {
+			return timing, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return timing, fmt.Errorf("failed to finish Parquet file: %w", err)
+	}
+	return timing, nil
+}
+
+// orderedStatement wraps statement with an ORDER BY on columns, or ORDER BY ALL for
+// OrderAllColumns
+func orderedStatement(statement string, columns []string) (string, error) {
+	query, err := selectFrom(statement)
+	if err != nil {
+		return "", err
+	}
+	if slices.Contains(columns, OrderAllColumns) {
+		if len(columns) > 1 {
+			return "", fmt.Errorf("EnsureOrder: %q cannot be combined with named columns", OrderAllColumns)
+		}
+		return query + " ORDER BY ALL", nil
+	}
+
+	quoted := make([]string, len(columns))
+	for i, col := range columns {
+		if quoted[i], err = quoteIdentifier(col); err != nil {
+			return "", fmt.Errorf("invalid EnsureOrder column %d: %w", i, err)
+		}
+	}
+	return query + " ORDER BY " + strings.Join(quoted, ", "), nil
+}
+
+func newParquetWriter(schema *arrow.Schema, w io.Writer) (*pqarrow.FileWriter, error) {
+	// Storing the Arrow schema lets Arrow readers restore types Parquet can't express directly
+	writer, err := pqarrow.NewFileWriter(schema, w, parquet.NewWriterProperties(), pqarrow.NewArrowWriterProperties(pqarrow.WithStoreSchema()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Parquet writer: %w", err)
+	}
+	return writer, nil
+}
+
+// arrowSchemaFromManifest maps manifest column types onto Arrow types for results without any
+// batches to take the schema from. Complex and unrecognized types fall back to strings, which is
+// how they appear in JSON_ARRAY results.
+func arrowSchemaFromManifest(schema Schema) *arrow.Schema {
+	fields := make([]arrow.Field, len(schema.Columns))
+	for i, col := range schema.Columns {
+		fields[i] = arrow.Field{Name: col.Name, Type: arrowType(col), Nullable: true}
+	}
+	return arrow.NewSchema(fields, nil)
+}
+
+func arrowType(col Column) arrow.DataType {
+	switch strings.ToUpper(col.TypeName) {
+	case "BOOLEAN":
+		return arrow.FixedWidthTypes.Boolean
+	case "BYTE", "TINYINT":
+		return arrow.PrimitiveTypes.Int8
+	case "SHORT", "SMALLINT":
+		return arrow.PrimitiveTypes.Int16
+	case "INT", "INTEGER":
+		return arrow.PrimitiveTypes.Int32
+	case "LONG", "BIGINT":
+		return arrow.PrimitiveTypes.Int64
+	case "FLOAT":
+		return arrow.PrimitiveTypes.Float32
+	case "DOUBLE":
+		return arrow.PrimitiveTypes.Float64
+	case "DECIMAL":
+		return &arrow.Decimal128Type{Precision: int32(col.TypePrecision), Scale: int32(col.TypeScale)}
+	case "DATE":
+		return arrow.FixedWidthTypes.Date32
+	case "TIMESTAMP":
+		return &arrow.TimestampType{Unit: arrow.Microsecond, TimeZone: "UTC"}
+	case "BINARY":
+		return arrow.BinaryTypes.Binary
+	default:
+		return arrow.BinaryTypes.String
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// phaseDivergenceTolerance is how far the summed phases may drift from the total duration
+// before the breakdown is reported as suspect
+const phaseDivergenceTolerance = 0.10
+
+// PhaseBreakdown splits a query's server-side latency into its phases
+type PhaseBreakdown struct {
+	Queuing     time.Duration
+	Planning    time.Duration
+	Compilation time.Duration
+	Execution   time.Duration
+	ResultFetch time.Duration
+}
+
+// Total returns the sum of all phases
+func (p PhaseBreakdown) Total() time.Duration {
+	return p.Queuing + p.Planning + p.Compilation + p.Execution + p.ResultFetch
+}
+
+// divergesFrom reports whether the summed phases differ from total by more than 10%
+func (p PhaseBreakdown) divergesFrom(total time.Duration) bool {
+	if total <= 0 {
+		return p.Total() > 0
+	}
+	diff := (p.Total() - total).Abs()
+	return float64(diff) > phaseDivergenceTolerance*float64(total)
+}
+
+// Phases maps the system.query.history duration columns onto a PhaseBreakdown. Queuing covers
+// both waiting for compute and waiting at capacity. The table does not report planning on its
+// own, so Planning is zero and planning time is part of Compilation.
+func (q QueryHistoryResponse) Phases() PhaseBreakdown {
+	return PhaseBreakdown{
+		Queuing:     msDuration(q.WaitingForComputeMs + q.WaitingAtCapacityMs),
+		Compilation: msDuration(q.CompilationDurationMs),
+		Execution:   msDuration(q.ExecutionDurationMs),
+		ResultFetch: msDuration(q.ResultFetchDurationMs),
+	}
+}
+
+// Phases maps the history endpoint's metrics onto a PhaseBreakdown. Queuing runs from the
+// earliest queue start to the start of compilation; it is zero when the query never queued.
+// A response without metrics yields an empty breakdown.
+func (h HistoryQueryResponse) Phases() PhaseBreakdown {
+	m := h.Metrics
+	if m == nil {
+		return PhaseBreakdown{}
+	}
+
+	var queuing time.Duration
+	queueStart := m.ProvisioningQueueStartTimestamp
+	if m.OverloadingQueueStartTimestamp > 0 && (queueStart == 0 || m.OverloadingQueueStartTimestamp < queueStart) {
+		queueStart = m.OverloadingQueueStartTimestamp
+	}
+	if queueStart > 0 && m.QueryCompilationStartTimestamp > queueStart {
+		queuing = msDuration(m.QueryCompilationStartTimestamp - queueStart)
+	}
+
+	return PhaseBreakdown{
+		Queuing:     queuing,
+		Planning:    msDuration(m.PlanningTimeMs),
+		Compilation: msDuration(m.CompilationTimeMs),
+		Execution:   msDuration(m.ExecutionTimeMs),
+		ResultFetch: msDuration(m.ResultFetchTimeMs),
+	}
+}
+
+// checkPhases warns when a breakdown does not add up to the reported total, which usually means
+// a history field was renamed or changed meaning upstream
+func (c *DatabricksRESTClient) checkPhases(ctx context.Context, statementID string, phases PhaseBreakdown, totalMs int64) {
+	if !phases.divergesFrom(msDuration(totalMs)) {
+		return
+	}
+	c.logger.WarnContext(ctx, "query phases do not sum to total duration",
+		slog.String("statement_id", statementID),
+		slog.Int64("phases_ms", phases.Total().Milliseconds()),
+		slog.Int64("duration_ms", totalMs))
+}
+
+func msDuration(ms int64) time.Duration {
+	return time.Duration(ms) * time.Millisecond
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrUnauthorized is returned by Ping when the workspace rejects the client's credentials
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrWarehouseUnavailable is returned by Ping when the warehouse does not run the probe
+	// statement within its wait timeout, e.g. because it is stopped and slow to start
+	ErrWarehouseUnavailable = errors.New("warehouse unavailable")
+)
+
+// pingWaitTimeout bounds how long Ping waits for the warehouse
+const pingWaitTimeout = "10s"
+
+// Ping checks credentials and warehouse reachability by running SELECT 1. It returns nil only
+// if the statement SUCCEEDED; a 401 or 403 wraps ErrUnauthorized, and a statement still queued
+// or canceled after 10s wraps ErrWarehouseUnavailable.
+func (c *DatabricksRESTClient) Ping(ctx context.Context) error {
+	reqBody := c.newStatementRequest("SELECT 1")
+	reqBody.WaitTimeout = pingWaitTimeout
+	reqBody.OnWaitTimeout = "CANCEL"
+
+	_, resp, err := c.executeStatement(ctx, reqBody)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.IsAuth() {
+		return fmt.Errorf("ping failed: %w: %w", ErrUnauthorized, err)
+	}
+	if err != nil {
+		return fmt.Errorf("ping failed: %w", err)
+	}
+
+	if resp.Status.State != StateSucceeded {
+		return fmt.Errorf("ping of warehouse %s ended in state %s: %w", c.warehouseID, resp.Status.State, ErrWarehouseUnavailable)
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// PlanNode is one operator of a physical plan with the attributes that decide its behavior
+type PlanNode struct {
+	// Depth is the node's level in the operator tree, 0 for the root
+	Depth int `json:"depth"`
+	// Operator is the operator with its inline arguments, e.g. "BroadcastHashJoin Inner BuildRight"
+	Operator   string            `json:"operator"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// PlanFingerprint is the normalized shape of a physical plan, in pre-order. Expression IDs,
+// file locations and statistics are left out, so the same plan on another day or table version
+// fingerprints identically. It marshals to stable JSON for keeping in git.
+type PlanFingerprint struct {
+	Nodes []PlanNode `json:"nodes"`
+}
+
+// Kinds of PlanChange
+const (
+	PlanNodeAdded        = "ADDED"
+	PlanNodeRemoved      = "REMOVED"
+	PlanAttributeChanged = "ATTRIBUTE_CHANGED"
+)
+
+// PlanChange is one difference between two plan fingerprints
+type PlanChange struct {
+	Kind string
+	// Node is the operator that was added, removed or changed
+	Node string
+	// Attribute, Before and After describe an ATTRIBUTE_CHANGED change; a missing attribute is ""
+	Attribute     string
+	Before, After string
+}
+
+func (c PlanChange) String() string {
+	switch c.Kind {
+	case PlanAttributeChanged:
+		return fmt.Sprintf("%s: %s changed from %q to %q", c.Node, c.Attribute, c.Before, c.After)
+	case PlanNodeAdded:
+		return "added " + c.Node
+	default:
+		return "removed " + c.Node
+	}
+}
+
+// planAttributes are the detail fields of EXPLAIN FORMATTED kept in a fingerprint: the ones that
+// show whether a scan prunes and how a join or aggregate is keyed
+var planAttributes = []string{
+	"PartitionFilters", "PushedFilters", "DataFilters",
+	"Join type", "Join condition", "Left keys", "Right keys",
+	"Keys", "Functions", "Condition", "Arguments",
+}
+
+var (
+	// planTreeLine splits an operator tree line into its connector prefix, operator and node ID
+	planTreeLine = regexp.MustCompile(`^([ :|+\-]*)(?:\* )?(.+?)(?: \((\d+)\))?$`)
+	// planDetailHeader starts the detail block of node (n)
+	planDetailHeader = regexp.MustCompile(`^\((\d+)\) `)
+	// expressionID matches the #123 / #123L suffixes Spark gives every attribute reference
+	expressionID = regexp.MustCompile(`#\d+L?`)
+	// planVolatile matches plan values that change between runs of the same plan
+	planVolatile = regexp.MustCompile(`\bplan_id=\d+|\bisFinalPlan=\w+|\[(?:dbfs|s3a?|abfss?|gs|file):[^\]]*\]`)
+)
+
+// CapturePlan runs EXPLAIN FORMATTED on a statement and fingerprints its physical plan
+func (c *DatabricksRESTClient) CapturePlan(ctx context.Context, statement string) (PlanFingerprint, error) {
+	plan, err := c.ExplainStatement(ctx, statement)
+	if err != nil {
+		return PlanFingerprint{}, err
+	}
+	fp := ParsePlan(plan)
+	if len(fp.Nodes) == 0 {
+		return PlanFingerprint{}, fmt.Errorf("no physical plan in EXPLAIN output for %q", summarizeStatement(statement))
+	}
+	return fp, nil
+}
+
+// ParsePlan fingerprints the text of an EXPLAIN FORMATTED plan
+func ParsePlan(plan string) PlanFingerprint {
+	lines := strings.Split(plan, "\n")
+
+	// Detail blocks, "(n) Operator" followed by "Key: value" lines, come after the tree
+	details := map[string]map[string]string{}
+	var current map[string]string
+	for _, line := range lines {
+		if m := planDetailHeader.FindStringSubmatch(line); m != nil {
+			current = map[string]string{}
+			details[m[1]] = current
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			current = nil
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		key = strings.TrimSpace(key)
+		if !ok || !slices.Contains(planAttributes, key) {
+			continue
+		}
+		if value = normalizePlanText(value); value != "" {
+			current[key] = value
+		}
+	}
+
+	var fp PlanFingerprint
+	inTree := false
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "== Physical Plan ==") {
+			inTree = true
+			continue
+		}
+		if !inTree {
+			continue
+		}
+		if trimmed == "" {
+			break
+		}
+		m := planTreeLine.FindStringSubmatch(line)
+		if m == nil || strings.HasPrefix(m[2], "==") {
+			continue
+		}
+		node := PlanNode{
+			// Each tree level indents by three characters: "+- ", ":- ", ":  " or "   "
+			Depth:    len(m[1]) / 3,
+			Operator: normalizePlanText(m[2]),
+		}
+		if attrs := details[m[3]]; len(attrs) > 0 {
+			node.Attributes = attrs
+		}
+		fp.Nodes = append(fp.Nodes, node)
+	}
+	return fp
+}
+
+// normalizePlanText strips expression IDs and run-specific values from plan text
+func normalizePlanText(s string) string {
+	s = expressionID.ReplaceAllString(s, "")
+	s = planVolatile.ReplaceAllString(s, "")
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// ComparePlans lists how plan b differs from plan a. Nodes are matched by operator and depth in
+// tree order, so an inserted exchange shows as one added node rather than shifting every node
+// below it; matched nodes are then compared attribute by attribute. Equal plans yield nil.
+func ComparePlans(a, b PlanFingerprint) []PlanChange {
+	key := func(n PlanNode) string { return strconv.Itoa(n.Depth) + " " + n.Operator }
+
+	// lcs[i][j] is the longest common subsequence of a.Nodes[i:] and b.Nodes[j:]
+	lcs := make([][]int, len(a.Nodes)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b.Nodes)+1)
+	}
+	for i := len(a.Nodes) - 1; i >= 0; i-- {
+		for j := len(b.Nodes) - 1; j >= 0; j-- {
+			if key(a.Nodes[i]) == key(b.Nodes[j]) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var changes []PlanChange
+	i, j := 0, 0
+	for i < len(a.Nodes) || j < len(b.Nodes) {
+		switch {
+		case i < len(a.Nodes) && j < len(b.Nodes) && key(a.Nodes[i]) == key(b.Nodes[j]):
+			changes = append(changes, compareNodeAttributes(a.Nodes[i], b.Nodes[j])...)
+			i++
+			j++
+		case j < len(b.Nodes) && (i == len(a.Nodes) || lcs[i][j+1] >= lcs[i+1][j]):
+			changes = append(changes, PlanChange{Kind: PlanNodeAdded, Node: b.Nodes[j].Operator})
+			j++
+		default:
+			changes = append(changes, PlanChange{Kind: PlanNodeRemoved, Node: a.Nodes[i].Operator})
+			i++
+		}
+	}
+	return changes
+}
+
+func compareNodeAttributes(a, b PlanNode) []PlanChange {
+	names := slices.Sorted(maps.Keys(a.Attributes))
+	for name := range b.Attributes {
+		if _, ok := a.Attributes[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	var changes []PlanChange
+	for _, name := range names {
+		if a.Attributes[name] != b.Attributes[name] {
+			changes = append(changes, PlanChange{
+				Kind:      PlanAttributeChanged,
+				Node:      a.Operator,
+				Attribute: name,
+				Before:    a.Attributes[name],
+				After:     b.Attributes[name],
+			})
+		}
+	}
+	return changes
+}
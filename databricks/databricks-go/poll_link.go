@@ -0,0 +1,70 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// statementPath is the poll path built from a statement ID, used when the server sends no link
+func statementPath(statementID string) string {
+	return "/api/2.0/sql/statements/" + statementID
+}
+
+// PollPath returns the workspace path to poll for the statement's state: the link the server
+// sent in its Location or Link header when there was one, otherwise the path built from
+// StatementID
+func (resp *StatementExecutionResponse) PollPath() string {
+	if resp.pollLink != "" {
+		return resp.pollLink
+	}
+	return statementPath(resp.StatementID)
+}
+
+// pollLinkFromHeader returns the poll link from a Location header, or from a Link header entry
+// with rel="poll" or rel="monitor", as a path on the workspace. Links to any other host are
+// ignored so the workspace token is never sent elsewhere; "" means no usable link.
+func pollLinkFromHeader(hostname string, header http.Header) string {
+	if location := header.Get("Location"); location != "" {
+		return workspaceLinkPath(hostname, location)
+	}
+	for _, value := range header.Values("Link") {
+		for link := range strings.SplitSeq(value, ",") {
+			target, params, ok := strings.Cut(strings.TrimSpace(link), ";")
+			if !ok || !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			if rel := linkRel(params); rel != "poll" && rel != "monitor" {
+				continue
+			}
+			if path := workspaceLinkPath(hostname, target[1:len(target)-1]); path != "" {
+				return path
+			}
+		}
+	}
+	return ""
+}
+
+// linkRel returns the rel parameter of a Link header entry's parameters
+func linkRel(params string) string {
+	for param := range strings.SplitSeq(params, ";") {
+		name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+		if ok && strings.EqualFold(name, "rel") {
+			return strings.ToLower(strings.Trim(value, `"`))
+		}
+	}
+	return ""
+}
+
+// workspaceLinkPath reduces a relative or absolute link to its path and query when it points
+// at the workspace, returning "" otherwise
+func workspaceLinkPath(hostname, link string) string {
+	u, err := url.Parse(link)
+	if err != nil || !strings.HasPrefix(u.Path, "/api/") {
+		return ""
+	}
+	if u.Host != "" && !strings.EqualFold(u.Host, hostname) {
+		return ""
+	}
+	return u.RequestURI()
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// redacted replaces secrets in recordings
+const redacted = "REDACTED"
+
+// recording is one request/response pair as stored on disk
+type recording struct {
+	Method          string      `json:"method"`
+	URL             string      `json:"url"`
+	RequestHeaders  http.Header `json:"request_headers"`
+	RequestBody     []byte      `json:"request_body,omitempty"`
+	StatusCode      int         `json:"status_code"`
+	ResponseHeaders http.Header `json:"response_headers"`
+	ResponseBody    []byte      `json:"response_body,omitempty"`
+}
+
+// WithRecorder saves every request/response pair the client makes as a JSON file in dir, named
+// by a hash of the method, path, query and body, for later use with WithReplayer. Authorization
+// headers, OAuth access tokens and the signatures of EXTERNAL_LINKS presigned URLs are redacted,
+// so recordings can be committed. Requests still go to the workspace through the transport
+// configured so far.
+func WithRecorder(dir string) ClientOption {
+	return func(c *DatabricksRESTClient) {
+		next := http.DefaultTransport
+		if c.httpClient != nil && c.httpClient.Transport != nil {
+			next = c.httpClient.Transport
+		}
+		c.httpClient = c.httpClientWithTransport(&recordingTransport{dir: dir, next: next, keys: recordingKeys{workspaceHost: c.hostname}})
+	}
+}
+
+// WithReplayer serves responses from the recordings a WithRecorder client wrote to dir instead of
+// contacting the workspace. A request without a recording fails. Repeated identical requests,
+// such as status polls, replay their recordings in order, and the last one once they run out.
+func WithReplayer(dir string) ClientOption {
+	return func(c *DatabricksRESTClient) {
+		c.httpClient = c.httpClientWithTransport(&replayingTransport{dir: dir, keys: recordingKeys{workspaceHost: c.hostname}})
+	}
+}
+
+// recordingKeys numbers repeats of the same request so that each response is kept
+type recordingKeys struct {
+	// workspaceHost tells workspace API requests from presigned cloud storage downloads
+	workspaceHost string
+
+	mu   sync.Mutex
+	seen map[string]int
+}
+
+// isPresigned reports whether req goes to a presigned EXTERNAL_LINKS URL rather than the
+// workspace. Its query string carries the signature.
+func (k *recordingKeys) isPresigned(req *http.Request) bool {
+	return req.URL.Host != k.workspaceHost
+}
+
+// next returns the request's hash and how many times it was seen before. The workspace host is
+// left out so recordings replay against any host; a presigned download is keyed by host and
+// path without its signature, matching the redacted link the replayed response points to.
+func (k *recordingKeys) next(req *http.Request, body []byte) (string, int) {
+	h := sha256.New()
+	if k.isPresigned(req) {
+		fmt.Fprintf(h, "%s %s%s\n", req.Method, req.URL.Host, req.URL.Path)
+	} else {
+		fmt.Fprintf(h, "%s %s?%s\n", req.Method, req.URL.Path, req.URL.RawQuery)
+	}
+	h.Write(body)
+	hash := hex.EncodeToString(h.Sum(nil))[:16]
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.seen == nil {
+		k.seen = make(map[string]int)
+	}
+	n := k.seen[hash]
+	k.seen[hash]++
+	return hash, n
+}
+
+func recordingPath(dir, hash string, n int) string {
+	return filepath.Join(dir, fmt.Sprintf("%s-%03d.json", hash, n))
+}
+
+type recordingTransport struct {
+	dir  string
+	next http.RoundTripper
+	keys recordingKeys
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	rec := recording{
+		Method:          req.Method,
+		URL:             req.URL.String(),
+		RequestHeaders:  req.Header.Clone(),
+		RequestBody:     reqBody,
+		StatusCode:      resp.StatusCode,
+		ResponseHeaders: resp.Header,
+		ResponseBody:    respBody,
+	}
+	if rec.RequestHeaders.Get("Authorization") != "" {
+		rec.RequestHeaders.Set("Authorization", redacted)
+	}
+	switch {
+	case t.keys.isPresigned(req):
+		// The link's http_headers may carry encryption keys, so no download headers are kept
+		rec.URL = withoutQuery(req.URL)
+		rec.RequestHeaders = nil
+	case req.URL.Path == "/oidc/v1/token":
+		rec.ResponseBody = redactAccessToken(respBody)
+	case bytes.Contains(respBody, []byte(`"external_link"`)):
+		rec.ResponseBody = redactExternalLinks(respBody)
+	}
+
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode recording: %w", err)
+	}
+	if err := os.MkdirAll(t.dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create recording directory: %w", err)
+	}
+	hash, n := t.keys.next(req, reqBody)
+	if err := os.WriteFile(recordingPath(t.dir, hash, n), data, 0o644); err != nil {
+		return nil, fmt.Errorf("failed to write recording: %w", err)
+	}
+	return resp, nil
+}
+
+type replayingTransport struct {
+	dir  string
+	keys recordingKeys
+}
+
+func (t *replayingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	hash, n := t.keys.next(req, reqBody)
+
+	// Past the last recording of a repeated request, keep serving the last one
+	var data []byte
+	for ; n >= 0; n-- {
+		data, err = os.ReadFile(recordingPath(t.dir, hash, n))
+		if !os.IsNotExist(err) {
+			break
+		}
+	}
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no recording for %s %s in %s", req.Method, req.URL.Path, t.dir)
+		}
+		return nil, fmt.Errorf("failed to read recording: %w", err)
+	}
+
+	var rec recording
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("failed to parse recording %s-%03d: %w", hash, n, err)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rec.StatusCode, http.StatusText(rec.StatusCode)),
+		StatusCode:    rec.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        rec.ResponseHeaders,
+		Body:          io.NopCloser(bytes.NewReader(rec.ResponseBody)),
+		ContentLength: int64(len(rec.ResponseBody)),
+		Request:       req,
+	}, nil
+}
+
+// readRequestBody reads the request body and puts an unread copy back on the request
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// withoutQuery returns u without its query string and fragment
+func withoutQuery(u *url.URL) string {
+	stripped := *u
+	stripped.RawQuery = ""
+	stripped.Fragment = ""
+	return stripped.String()
+}
+
+// redactExternalLinks strips the signed query string from every external_link in a statement or
+// chunk response and blanks the link's http_headers. The expiration is dropped as well: the
+// redacted link never expires on replay. A body that can't be parsed is replaced entirely rather
+// than risk keeping a signature.
+func redactExternalLinks(body []byte) []byte {
+	var resp map[string]any
+	dec := json.NewDecoder(bytes.NewReader(body))
+	// Keep row counts and offsets exact when re-encoding
+	dec.UseNumber()
+	if err := dec.Decode(&resp); err != nil {
+		return []byte(`"` + redacted + `"`)
+	}
+
+	links := resp
+	if result, ok := resp["result"].(map[string]any); ok {
+		links = result
+	}
+	list, _ := links["external_links"].([]any)
+	for _, item := range list {
+		link, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		if raw, ok := link["external_link"].(string); ok {
+			if u, err := url.Parse(raw); err == nil {
+				link["external_link"] = withoutQuery(u)
+			} else {
+				link["external_link"] = redacted
+			}
+		}
+		if headers, ok := link["http_headers"].(map[string]any); ok {
+			for name := range headers {
+				headers[name] = redacted
+			}
+		}
+		delete(link, "expiration")
+	}
+
+	redactedBody, err := json.Marshal(resp)
+	if err != nil {
+		return []byte(`"` + redacted + `"`)
+	}
+	return redactedBody
+}
+
+// redactAccessToken blanks the access_token of an OAuth token response
+func redactAccessToken(body []byte) []byte {
+	var fields map[string]any
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return body
+	}
+	if _, ok := fields["access_token"]; ok {
+		fields["access_token"] = redacted
+	}
+	redactedBody, err := json.Marshal(fields)
+	if err != nil {
+		return body
+	}
+	return redactedBody
+}
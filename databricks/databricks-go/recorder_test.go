@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"databricks-go-timing-test/testserver"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	dir := t.TempDir()
+	srv := testserver.NewMockServer()
+	handler := testserver.HandlerFor(srv)
+	handler.AddStatement(testserver.Statement{Text: "SELECT n FROM range(5)", Rows: testserver.IntRows(5), ChunkSize: 2})
+	handler.AddStatement(testserver.Statement{Text: "SELECT 1", Rows: testserver.IntRows(1), RunningPolls: 2})
+
+	recorder := NewDatabricksRESTClient(srv.Listener.Addr().String(), "dapi-secret", "warehouse",
+		WithHTTPClient(srv.Client()), WithRecorder(dir))
+	recordedRows, _, err := recorder.ExecuteAndFetchRows(context.Background(), "SELECT n FROM range(5)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
+	defer cancel()
+	if _, err := recorder.ExecuteStatementWithREST(ctx, "SELECT 1"); err != nil {
+		t.Fatal(err)
+	}
+	srv.Close()
+	assertNoSecret(t, dir, "dapi-secret")
+
+	// The server is gone and the replaying client points at a host that does not exist
+	replayer := NewDatabricksRESTClient("replay.invalid", "other-token", "warehouse", WithReplayer(dir))
+	rows, timing, err := replayer.ExecuteAndFetchRows(context.Background(), "SELECT n FROM range(5)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(rows) != fmt.Sprint(recordedRows) || timing.RowCount != 5 {
+		t.Errorf("replayed rows = %v (count %d), recorded %v", rows, timing.RowCount, recordedRows)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 4*time.Second)
+	defer cancel()
+	polled, err := replayer.ExecuteStatementWithREST(ctx, "SELECT 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if polled.State != StateSucceeded {
+		t.Errorf("replayed polled statement state = %s, want %s", polled.State, StateSucceeded)
+	}
+
+	if _, err := replayer.ExecuteStatementWithREST(context.Background(), "SELECT 2"); err == nil || !strings.Contains(err.Error(), "no recording") {
+		t.Errorf("unrecorded statement err = %v, want a missing recording error", err)
+	}
+}
+
+func TestRecorderRedactsExternalLinks(t *testing.T) {
+	const signature = "X-Amz-Signature=deadbeef"
+
+	storage := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.URL.RawQuery, signature) || r.Header.Get("x-amz-server-side-encryption-customer-key") != "sse-key" {
+			http.Error(w, "signature does not match", http.StatusForbidden)
+			return
+		}
+		fmt.Fprint(w, `[["1"],["2"]]`)
+	}))
+	defer storage.Close()
+
+	workspace := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"statement_id": "01f0-0001",
+			"status":       map[string]any{"state": StateSucceeded},
+			"manifest": map[string]any{
+				"format":            FormatJSONArray,
+				"schema":            map[string]any{"column_count": 1, "columns": []any{map[string]any{"name": "n", "type_name": "BIGINT", "position": 0}}},
+				"total_chunk_count": 1,
+				"total_row_count":   2,
+			},
+			"result": map[string]any{
+				"external_links": []any{map[string]any{
+					"chunk_index":   0,
+					"row_count":     2,
+					"external_link": storage.URL + "/bucket/chunk-0?X-Amz-Expires=900&" + signature,
+					"expiration":    time.Now().Add(15 * time.Minute).Format(time.RFC3339),
+					"http_headers":  map[string]string{"x-amz-server-side-encryption-customer-key": "sse-key"},
+				}},
+			},
+		})
+	}))
+	defer workspace.Close()
+
+	dir := t.TempDir()
+	recorder := NewDatabricksRESTClient(workspace.Listener.Addr().String(), "token", "warehouse",
+		WithHTTPClient(workspace.Client()), WithRecorder(dir))
+	if _, _, err := recorder.ExecuteAndFetchExternalLinks(context.Background(), "SELECT n"); err != nil {
+		t.Fatal(err)
+	}
+	storage.Close()
+	workspace.Close()
+	assertNoSecret(t, dir, "deadbeef")
+	assertNoSecret(t, dir, "sse-key")
+
+	// The signature is gone and the recorded expiration with it, so the links replay at any time
+	replayer := NewDatabricksRESTClient("replay.invalid", "token", "warehouse", WithReplayer(dir))
+	rows, _, err := replayer.ExecuteAndFetchExternalLinks(context.Background(), "SELECT n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(rows) != "[[1] [2]]" {
+		t.Errorf("replayed rows = %v", rows)
+	}
+}
+
+func TestRedactExternalLinksUnparseable(t *testing.T) {
+	body := []byte(`{"external_link": "https://bucket/chunk?sig=secret"`)
+	if got := redactExternalLinks(body); strings.Contains(string(got), "secret") {
+		t.Errorf("redactExternalLinks kept the signature of a truncated body: %s", got)
+	}
+}
+
+// assertNoSecret fails if any recording in dir contains secret
+func assertNoSecret(t *testing.T, dir, secret string) {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no recordings in %s: %v", dir, err)
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), secret) {
+			t.Errorf("%s contains %q", filepath.Base(file), secret)
+		}
+	}
+}
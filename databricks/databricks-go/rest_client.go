@@ -0,0 +1,597 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// DatabricksRESTClient executes statements through the SQL Statement Execution API.
+//
+// A client is safe for concurrent use by multiple goroutines and is meant to be shared: the
+// HTTP client, rate limiter and logger are concurrency-safe, and the OAuthProvider token and
+// history cache are guarded by their own mutexes. The exported fields are configuration and must
+// be set before the client is shared; changing them while requests are in flight is a data race.
+type DatabricksRESTClient struct {
+	// MaxRetries is how many times a request is retried after a response that IsRetryable
+	MaxRetries int
+	// BaseBackoff is the initial retry delay; it doubles per attempt and is fully jittered
+	BaseBackoff time.Duration
+	// CloseAfterFetch makes ExecuteAndFetchRows, ExecuteAndFetchExternalLinks and StreamRows close
+	// the statement once every chunk has been fetched, releasing its server-side result
+	CloseAfterFetch bool
+	// AllowShortVacuumRetention lets VacuumTable retain less than the 168-hour default. The
+	// warehouse enforces its own retention check as well.
+	AllowShortVacuumRetention bool
+	// Converter decodes result cells into Go values; nil uses DefaultTypeConverter
+	Converter TypeConverter
+
+	hostname    string
+	warehouseID string
+	httpClient  *http.Client
+	limiter     *rate.Limiter
+	logger      *slog.Logger
+	// userAgent is sent on every request; WithUserAgent appends to it
+	userAgent string
+	// durations remembers how long recent statements took, keyed by statement text
+	durations *lruCache
+	// retryBudget limits retries across all requests when enabled with WithRetryBudget
+	retryBudget *retryBudget
+	// cache holds finished history lookups when enabled with WithCache
+	cache *lruCache
+	// credentials supplies the bearer token; a StaticTokenProvider for the constructor's token
+	// unless replaced with WithCredentialProvider
+	credentials CredentialProvider
+	// catalog and schema are sent with every statement when set with WithNamespace
+	catalog, schema string
+}
+
+// NewDatabricksRESTClient creates a REST client for the given workspace and warehouse
+func NewDatabricksRESTClient(hostname, token, warehouseID string, opts ...ClientOption) *DatabricksRESTClient {
+	c := &DatabricksRESTClient{
+		MaxRetries:  defaultMaxRetries,
+		BaseBackoff: defaultBaseBackoff,
+		hostname:    hostname,
+		credentials: StaticTokenProvider(token),
+		warehouseID: warehouseID,
+		httpClient:  newDefaultHTTPClient(),
+		logger:      slog.New(slog.DiscardHandler),
+		userAgent:   defaultUserAgent(),
+		durations:   newLRUCache(observedDurationsSize),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// defaultHTTPTimeout bounds each request; the API may hold one open for up to the 50s wait_timeout
+const defaultHTTPTimeout = 60 * time.Second
+
+func newDefaultHTTPClient() *http.Client {
+	return &http.Client{Timeout: defaultHTTPTimeout}
+}
+
+// Result dispositions accepted by the Statement Execution API
+const (
+	DispositionInline        = "INLINE"
+	DispositionExternalLinks = "EXTERNAL_LINKS"
+)
+
+// Result formats accepted by the Statement Execution API
+const (
+	FormatJSONArray   = "JSON_ARRAY"
+	FormatArrowStream = "ARROW_STREAM"
+	FormatCSV         = "CSV"
+)
+
+// StatementExecutionRequest is the payload for POST /api/2.0/sql/statements
+type StatementExecutionRequest struct {
+	Statement   string `json:"statement"`
+	WarehouseID string `json:"warehouse_id"`
+	WaitTimeout string `json:"wait_timeout,omitempty"`
+	// OnWaitTimeout is CONTINUE (the default) or CANCEL, which cancels the statement if it has
+	// not finished within WaitTimeout
+	OnWaitTimeout string `json:"on_wait_timeout,omitempty"`
+	Disposition   string `json:"disposition,omitempty"`
+	Format        string `json:"format,omitempty"`
+	// Parameters bind :name placeholders in Statement server-side
+	Parameters []StatementParameter `json:"parameters,omitempty"`
+	ByteLimit  int64                `json:"byte_limit,omitempty"`
+	RowLimit   int64                `json:"row_limit,omitempty"`
+	// SessionID runs the statement in a session opened with OpenSession
+	SessionID string `json:"session_id,omitempty"`
+	// Catalog and Schema resolve unqualified table names, like USE CATALOG and USE SCHEMA
+	Catalog string `json:"catalog,omitempty"`
+	Schema  string `json:"schema,omitempty"`
+}
+
+// StatementParameter is a named value bound to a :name placeholder. Type is a SQL type such as
+// STRING, INT or TIMESTAMP and defaults to STRING when empty.
+type StatementParameter struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	Type  string `json:"type,omitempty"`
+}
+
+// StatementExecutionResponse represents the response structure from the Statement Execution API
+type StatementExecutionResponse struct {
+	StatementID string          `json:"statement_id"`
+	Status      StatementStatus `json:"status"`
+	Manifest    Manifest        `json:"manifest"`
+	Result      ResultData      `json:"result"`
+	SessionID   string          `json:"session_id,omitempty"`
+
+	// pollLink is the workspace path the server's Location or Link header said to poll, if any
+	pollLink string
+}
+
+// Statement execution states reported in status.state
+const (
+	StatePending   = "PENDING"
+	StateRunning   = "RUNNING"
+	StateSucceeded = "SUCCEEDED"
+	StateFailed    = "FAILED"
+	StateCanceled  = "CANCELED"
+	StateClosed    = "CLOSED"
+)
+
+// StatementStatus holds the execution state of a statement
+type StatementStatus struct {
+	State string          `json:"state"`
+	Error *StatementError `json:"error,omitempty"`
+}
+
+// StatementError is the status.error object of a FAILED statement. Methods that observe a FAILED
+// statement return it as an error, so callers can use errors.As and branch on ErrorCode
+// (e.g. SYNTAX_ERROR vs RESOURCE_EXHAUSTED).
+type StatementError struct {
+	StatementID string `json:"-"`
+	ErrorCode   string `json:"error_code"`
+	Message     string `json:"message"`
+}
+
+func (e *StatementError) Error() string {
+	return fmt.Sprintf("statement %s failed: %s", e.StatementID, e.summary())
+}
+
+// summary renders the error as "<error_code>: <message>"
+func (e *StatementError) summary() string {
+	if e.ErrorCode == "" {
+		return e.Message
+	}
+	return e.ErrorCode + ": " + e.Message
+}
+
+// statementError returns the failure of a FAILED statement as a *StatementError, or nil
+func (r *StatementExecutionResponse) statementError() error {
+	if r.Status.State != StateFailed {
+		return nil
+	}
+	statementErr := &StatementError{StatementID: r.StatementID}
+	if r.Status.Error != nil {
+		statementErr.ErrorCode = r.Status.Error.ErrorCode
+		statementErr.Message = r.Status.Error.Message
+	}
+	return statementErr
+}
+
+// Manifest describes the shape of a statement's result set
+type Manifest struct {
+	Format          string `json:"format"`
+	Schema          Schema `json:"schema"`
+	TotalChunkCount int    `json:"total_chunk_count"`
+	TotalRowCount   int64  `json:"total_row_count"`
+	// Truncated is set when the result was cut short by a byte or row limit
+	Truncated bool `json:"truncated"`
+	// Chunks describes every chunk of the result
+	Chunks []ChunkInfo `json:"chunks,omitempty"`
+}
+
+// ChunkInfo is the manifest's description of one result chunk
+type ChunkInfo struct {
+	ChunkIndex int   `json:"chunk_index"`
+	RowOffset  int64 `json:"row_offset"`
+	RowCount   int64 `json:"row_count"`
+	ByteCount  int64 `json:"byte_count,omitempty"`
+}
+
+// ResultData holds one chunk of JSON_ARRAY result rows. Cells are strings, or nil for SQL NULL.
+type ResultData struct {
+	ChunkIndex            int            `json:"chunk_index"`
+	RowOffset             int64          `json:"row_offset"`
+	RowCount              int64          `json:"row_count"`
+	DataArray             [][]*string    `json:"data_array"`
+	NextChunkIndex        *int           `json:"next_chunk_index,omitempty"`
+	NextChunkInternalLink string         `json:"next_chunk_internal_link,omitempty"`
+	ExternalLinks         []ExternalLink `json:"external_links,omitempty"`
+}
+
+// Execution paths recorded in TimingInfo.Method
+const (
+	MethodGoDriver     = "GO_DRIVER"
+	MethodRESTAPI      = "REST_API"
+	MethodQueryHistory = "QUERY_HISTORY"
+)
+
+// TimingInfo captures client-side timing for a single statement execution
+type TimingInfo struct {
+	Method        string    `json:"method"`
+	QueryID       string    `json:"query_id"`
+	StatementText string    `json:"statement_text"`
+	StartTime     time.Time `json:"start_time"`
+	EndTime       time.Time `json:"end_time"`
+	DurationMs    int64     `json:"duration_ms"`
+	State         string    `json:"state,omitempty"`
+	RowCount      int64     `json:"row_count"`
+	ColumnCount   int       `json:"column_count"`
+	ErrorMessage  string    `json:"error_message,omitempty"`
+
+	// BytesDownloaded, ChunkDownloadMs and NumChunksFetched cover result chunks downloaded after
+	// the initial response, from chunk links or external links; DurationMs stays wall-clock
+	BytesDownloaded  int64 `json:"bytes_downloaded"`
+	ChunkDownloadMs  int64 `json:"chunk_download_ms"`
+	NumChunksFetched int   `json:"num_chunks_fetched"`
+	// Retries is how many times the submit request was retried before it was accepted
+	Retries int `json:"retries,omitempty"`
+	// Disposition is the result disposition the statement was submitted with
+	Disposition string `json:"disposition,omitempty"`
+	// Format is the result format the statement was submitted with
+	Format string `json:"format,omitempty"`
+	// Truncated is set when the result was cut short by RowLimit, ByteLimit or the INLINE size cap
+	Truncated bool `json:"truncated,omitempty"`
+	// ReturnedRowCount is how many rows the result's chunks hold, which is less than RowCount
+	// when the API reports more rows than it returned
+	ReturnedRowCount int64 `json:"returned_row_count,omitempty"`
+}
+
+// ExecuteStatementWithREST runs a statement synchronously and returns client-side timing.
+// If the statement FAILED, the timing is returned together with a *StatementError.
+// The call is traced as a databricks.statement.execute span under ctx. When ctx has a deadline,
+// wait_timeout is shortened to fit it, and a deadline under 5s submits asynchronously and polls.
+// A statement still queued when wait_timeout expires returns a *CapacityError.
+func (c *DatabricksRESTClient) ExecuteStatementWithREST(ctx context.Context, statement string) (*TimingInfo, error) {
+	timing, _, err := c.executeStatement(ctx, c.newStatementRequest(statement))
+	return timing, err
+}
+
+// ExecuteParameterized runs a statement whose :name placeholders are bound to params by the
+// server, so values never have to be quoted into the SQL text
+func (c *DatabricksRESTClient) ExecuteParameterized(ctx context.Context, statement string, params []StatementParameter) (*TimingInfo, error) {
+	reqBody := c.newStatementRequest(statement)
+	reqBody.Parameters = params
+	timing, _, err := c.executeStatement(ctx, reqBody)
+	return timing, err
+}
+
+// ExecuteStatement runs a statement and returns the full API response with every chunk's rows
+// assembled into resp.Result.DataArray, ready for ScanRows
+func (c *DatabricksRESTClient) ExecuteStatement(ctx context.Context, statement string) (*StatementExecutionResponse, *TimingInfo, error) {
+	timing, resp, err := c.executeStatement(ctx, c.newStatementRequest(statement))
+	if err != nil {
+		return resp, timing, err
+	}
+
+	ctx, transfer := withTransferStats(ctx)
+	defer transfer.applyTo(timing)
+	if err := c.materializeResult(ctx, resp); err != nil {
+		return nil, timing, err
+	}
+	return resp, timing, nil
+}
+
+// ExecuteAndFetchRows runs a statement and returns its rows decoded according to the manifest schema.
+// If the server returned fewer rows than the statement produced, the partial rows are returned
+// with an error wrapping ErrResultTruncated.
+func (c *DatabricksRESTClient) ExecuteAndFetchRows(ctx context.Context, statement string) ([][]any, *TimingInfo, error) {
+	return c.executeAndFetch(ctx, c.newStatementRequest(statement))
+}
+
+// ExecuteAndFetchExternalLinks runs a statement with the EXTERNAL_LINKS disposition and downloads
+// every chunk from its presigned URL. Use it for results that exceed the INLINE size cap.
+func (c *DatabricksRESTClient) ExecuteAndFetchExternalLinks(ctx context.Context, statement string) ([][]any, *TimingInfo, error) {
+	reqBody := c.newStatementRequest(statement)
+	reqBody.Disposition = DispositionExternalLinks
+	return c.executeAndFetch(ctx, reqBody)
+}
+
+func (c *DatabricksRESTClient) executeAndFetch(ctx context.Context, reqBody StatementExecutionRequest) ([][]any, *TimingInfo, error) {
+	timing, resp, err := c.executeStatement(ctx, reqBody)
+	if err != nil {
+		return nil, timing, err
+	}
+
+	ctx, transfer := withTransferStats(ctx)
+	defer transfer.applyTo(timing)
+
+	// Chunk 0 arrives inline; any further chunks are fetched by following the chunk links
+	rows, err := c.followChunks(ctx, resp.StatementID, resp.Manifest, &resp.Result)
+	if err != nil {
+		return nil, timing, err
+	}
+	if resp.Manifest.Truncated || int64(len(rows)) < resp.Manifest.TotalRowCount {
+		return rows, timing, truncationError(resp.StatementID, reqBody.Disposition, len(rows), resp.Manifest.TotalRowCount)
+	}
+
+	if c.CloseAfterFetch {
+		// The rows are complete, so return them even if releasing the result fails
+		if err := c.CloseStatement(ctx, resp.StatementID); err != nil {
+			return rows, timing, err
+		}
+	}
+	return rows, timing, nil
+}
+
+// GetStatementTiming fetches the current state of a previously submitted statement.
+// The call is traced as a databricks.statement.get span under ctx.
+func (c *DatabricksRESTClient) GetStatementTiming(ctx context.Context, statementID string) (*TimingInfo, error) {
+	startTime := time.Now()
+
+	resp, err := c.getStatement(ctx, statementID)
+	if err != nil {
+		return nil, err
+	}
+
+	endTime := time.Now()
+	timing := newTimingInfo(resp, "", startTime, endTime)
+	return timing, resp.statementError()
+}
+
+func (c *DatabricksRESTClient) getStatement(ctx context.Context, statementID string) (*StatementExecutionResponse, error) {
+	return c.getStatementAt(ctx, statementID, statementPath(statementID))
+}
+
+// getStatementAt GETs a statement's state from path, which is its server-provided poll link or
+// the path built from its ID
+func (c *DatabricksRESTClient) getStatementAt(ctx context.Context, statementID, path string) (resp *StatementExecutionResponse, err error) {
+	ctx, span := startSpan(ctx, spanStatementGet)
+	defer func() {
+		endStatementSpan(span, c.warehouseID, statementID, resp, err)
+	}()
+
+	resp = &StatementExecutionResponse{}
+	_, header, err := c.send(ctx, "GET", path, nil, resp)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("get of statement %s canceled while in flight: %w", statementID, ctx.Err())
+		}
+		return nil, fmt.Errorf("failed to get statement %s: %w", statementID, err)
+	}
+	resp.pollLink = pollLinkFromHeader(c.hostname, header)
+	c.logger.DebugContext(ctx, "statement polled",
+		slog.String("statement_id", statementID),
+		slog.String("state", resp.Status.State))
+	return resp, nil
+}
+
+// newStatementRequest builds the default synchronous INLINE/JSON_ARRAY request
+func (c *DatabricksRESTClient) newStatementRequest(statement string) StatementExecutionRequest {
+	return StatementExecutionRequest{
+		Statement:   statement,
+		WarehouseID: c.warehouseID,
+		WaitTimeout: "50s",
+		Disposition: DispositionInline,
+		Format:      FormatJSONArray,
+		Catalog:     c.catalog,
+		Schema:      c.schema,
+	}
+}
+
+func (c *DatabricksRESTClient) executeStatement(ctx context.Context, reqBody StatementExecutionRequest) (timing *TimingInfo, resp *StatementExecutionResponse, err error) {
+	ctx, span := startSpan(ctx, spanStatementExecute)
+	defer func() {
+		endStatementSpan(span, c.warehouseID, "", resp, err)
+	}()
+
+	startTime := time.Now()
+
+	// TimingInfo keeps the caller's statement text; only the submitted copy is tagged
+	submitted := reqBody
+	submitted.Statement = tagStatement(ctx, reqBody.Statement)
+	var async bool
+	submitted.WaitTimeout, async = waitTimeoutFor(ctx, reqBody.WaitTimeout)
+	if async {
+		// on_wait_timeout only applies to a non-zero wait
+		submitted.OnWaitTimeout = ""
+	}
+	resp = &StatementExecutionResponse{}
+	retries, header, err := c.send(ctx, "POST", "/api/2.0/sql/statements", submitted, resp)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, nil, fmt.Errorf("statement %q canceled while in flight: %w", summarizeStatement(reqBody.Statement), ctx.Err())
+		}
+		return nil, nil, fmt.Errorf("failed to execute statement after %d retries: %w", retries, err)
+	}
+	resp.pollLink = pollLinkFromHeader(c.hostname, header)
+	if async && !isTerminalState(resp.Status.State) {
+		expected, _ := c.ObservedDuration(reqBody.Statement)
+		if resp, err = c.pollStatement(ctx, resp.StatementID, resp.PollPath(), PollOptions{ExpectedDuration: expected}); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	endTime := time.Now()
+	if resp.Status.State == StateSucceeded {
+		c.durations.add(reqBody.Statement, endTime.Sub(startTime))
+	}
+	timing = newTimingInfo(resp, reqBody.Statement, startTime, endTime)
+	timing.Retries = retries
+	timing.Disposition = reqBody.Disposition
+	timing.Format = reqBody.Format
+	c.logger.DebugContext(ctx, "statement executed",
+		slog.String("statement_id", resp.StatementID),
+		slog.String("state", resp.Status.State),
+		slog.Int64("duration_ms", timing.DurationMs),
+		slog.Int("retries", retries))
+	if err := capacityError(resp, submitted.WaitTimeout, endTime.Sub(startTime)); err != nil {
+		return timing, resp, err
+	}
+	return timing, resp, resp.statementError()
+}
+
+// summarizeStatement collapses whitespace and truncates statement text for error messages
+func summarizeStatement(statement string) string {
+	const maxLen = 80
+	summary := strings.Join(strings.Fields(statement), " ")
+	if len(summary) > maxLen {
+		summary = summary[:maxLen] + "..."
+	}
+	return summary
+}
+
+func newTimingInfo(resp *StatementExecutionResponse, statement string, startTime, endTime time.Time) *TimingInfo {
+	timing := &TimingInfo{
+		Method:        MethodRESTAPI,
+		QueryID:       resp.StatementID,
+		StatementText: statement,
+		StartTime:     startTime,
+		EndTime:       endTime,
+		DurationMs:    endTime.Sub(startTime).Milliseconds(),
+		State:         resp.Status.State,
+		RowCount:      resp.Manifest.TotalRowCount,
+		ColumnCount:   resp.Manifest.Schema.ColumnCount,
+
+		Truncated:        resp.Manifest.Truncated,
+		ReturnedRowCount: resp.returnedRowCount(),
+	}
+	var statementErr *StatementError
+	if errors.As(resp.statementError(), &statementErr) {
+		timing.ErrorMessage = statementErr.summary()
+	}
+	return timing
+}
+
+// doJSON sends a request with an optional JSON body and decodes a JSON response into out
+func (c *DatabricksRESTClient) doJSON(ctx context.Context, method, path string, in, out any) error {
+	_, _, err := c.send(ctx, method, path, in, out)
+	return err
+}
+
+// send is doJSON with retries: retryable statuses are retried per the client's retry policy.
+// It returns the number of retries that were performed and the successful response's headers.
+func (c *DatabricksRESTClient) send(ctx context.Context, method, path string, in, out any) (int, http.Header, error) {
+	var payload []byte
+	if in != nil {
+		var err error
+		payload, err = json.Marshal(in)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to marshal request: %w", err)
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		status, header, respBody, err := c.roundTrip(ctx, method, path, payload)
+		if err != nil {
+			// A GET is safe to resend after a transport failure, e.g. a dropped chunk download
+			if method != http.MethodGet || attempt >= c.MaxRetries || ctx.Err() != nil || !c.allowRetry(ctx, path) {
+				return attempt, nil, err
+			}
+			c.logger.DebugContext(ctx, "retrying request after transport error",
+				slog.String("method", method),
+				slog.String("path", path),
+				slog.Int("attempt", attempt+1),
+				slog.String("error", err.Error()))
+			if err := sleepContext(ctx, c.retryDelay(attempt, nil)); err != nil {
+				return attempt, nil, err
+			}
+			continue
+		}
+
+		if status >= 200 && status < 300 {
+			c.retryBudget.success()
+			if out != nil && len(respBody) > 0 {
+				if err := json.Unmarshal(respBody, out); err != nil {
+					return attempt, header, fmt.Errorf("failed to parse response: %w", err)
+				}
+			}
+			return attempt, header, nil
+		}
+
+		apiErr := newAPIError(status, respBody)
+		if attempt >= c.MaxRetries || !shouldRetry(method, apiErr, respBody) || !c.allowRetry(ctx, path) {
+			return attempt, nil, apiErr
+		}
+		delay := c.retryDelay(attempt, header)
+		c.logger.DebugContext(ctx, "retrying request",
+			slog.String("method", method),
+			slog.String("path", path),
+			slog.Int("status", status),
+			slog.Int("attempt", attempt+1),
+			slog.Int64("delay_ms", delay.Milliseconds()))
+		if err := sleepContext(ctx, delay); err != nil {
+			return attempt, nil, errors.Join(apiErr, err)
+		}
+	}
+}
+
+// roundTrip performs a single HTTP request and reads the full response body
+func (c *DatabricksRESTClient) roundTrip(ctx context.Context, method, path string, payload []byte) (int, http.Header, []byte, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := c.newRequest(ctx, method, path, body)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.doHTTP(req)
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return resp.StatusCode, resp.Header, respBody, nil
+}
+
+// newRequest builds an authenticated workspace API request carrying the trace and correlation
+// headers from ctx
+func (c *DatabricksRESTClient) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, "https://"+c.hostname+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	authorization, err := c.authorization(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", authorization)
+	injectTraceContext(ctx, req.Header)
+	setCorrelationHeader(ctx, req.Header)
+	return req, nil
+}
+
+// authorization returns the Authorization header value from the client's credential provider
+func (c *DatabricksRESTClient) authorization(ctx context.Context) (string, error) {
+	token, err := c.credentials.Token(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get credentials: %w", err)
+	}
+	return "Bearer " + token, nil
+}
+
+// doHTTP sets the User-Agent, waits for the rate limiter if one is configured, and sends the request
+func (c *DatabricksRESTClient) doHTTP(req *http.Request) (*http.Response, error) {
+	req.Header.Set("User-Agent", c.userAgent)
+	if c.limiter != nil {
+		if err := c.limiter.Wait(req.Context()); err != nil {
+			return nil, fmt.Errorf("rate limiter: %w", err)
+		}
+	}
+	return c.httpClient.Do(req)
+}
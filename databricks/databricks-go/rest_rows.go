@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// RestRows iterates over a REST statement result with the same loop shape as sql.Rows:
+//
+//	for rows.Next() {
+//		if err := rows.Scan(&a, &b); err != nil { ... }
+//	}
+//	if err := rows.Err(); err != nil { ... }
+//
+// Chunks are fetched lazily as iteration reaches them, so only one chunk is held in memory.
+// A RestRows is not safe for concurrent use.
+type RestRows struct {
+	client *DatabricksRESTClient
+	ctx    context.Context
+	resp   *StatementExecutionResponse
+	timing *TimingInfo
+	// transfer accumulates chunk download metrics as iteration fetches chunks
+	transfer *transferStats
+
+	chunk  *ResultData
+	data   [][]*string
+	pos    int
+	row    []*string
+	err    error
+	closed bool
+}
+
+// QueryRows runs a statement and returns an iterator over its rows. The rows should be closed
+// when done; iterating to the end closes them automatically.
+func (c *DatabricksRESTClient) QueryRows(ctx context.Context, statement string) (*RestRows, error) {
+	timing, resp, err := c.executeStatement(ctx, c.newStatementRequest(statement))
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, transfer := withTransferStats(ctx)
+	rows := &RestRows{client: c, ctx: ctx, resp: resp, timing: timing, transfer: transfer}
+	if err := rows.load(&resp.Result); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// Next advances to the next row, fetching the next chunk when the current one is exhausted.
+// It returns false at the end of the result or on error; check Err to tell them apart.
+func (r *RestRows) Next() bool {
+	for r.pos >= len(r.data) {
+		if r.closed || r.err != nil || !r.nextChunk() {
+			r.row = nil
+			if r.err == nil {
+				r.err = r.Close()
+			}
+			return false
+		}
+	}
+	r.row = r.data[r.pos]
+	r.pos++
+	return true
+}
+
+// Scan copies the current row's columns into dest. Pointers to strings, integers, floats, bools,
+// time.Time, big.Rat and Decimal are converted from the cell text, so *string, *big.Rat and
+// *Decimal keep DECIMAL values exact while *float64 rounds them. *any receives the value produced
+// by the client's TypeConverter, and sql.Scanner implementations are given that value too. A
+// NULL cell sets the destination to its zero value.
+func (r *RestRows) Scan(dest ...any) error {
+	if r.row == nil {
+		return errors.New("Scan called without a successful Next")
+	}
+	if len(dest) != len(r.row) {
+		return fmt.Errorf("expected %d destination arguments in Scan, not %d", len(r.row), len(dest))
+	}
+
+	columns := r.resp.Manifest.Schema.Columns
+	for i, d := range dest {
+		var col Column
+		if i < len(columns) {
+			col = columns[i]
+		}
+		if err := r.scanColumn(col, r.row[i], d); err != nil {
+			return fmt.Errorf("column %d (%s): %w", i, col.Name, err)
+		}
+	}
+	return nil
+}
+
+func (r *RestRows) scanColumn(col Column, cell *string, dest any) error {
+	switch d := dest.(type) {
+	case sql.Scanner:
+		value, err := r.client.typeConverter()(col, cell)
+		if err != nil {
+			return err
+		}
+		return d.Scan(value)
+	case *any:
+		value, err := r.client.typeConverter()(col, cell)
+		if err != nil {
+			return err
+		}
+		*d = value
+		return nil
+	}
+
+	ptr := reflect.ValueOf(dest)
+	if ptr.Kind() != reflect.Pointer || ptr.IsNil() {
+		return fmt.Errorf("destination %T is not a non-nil pointer", dest)
+	}
+	return assignCell(ptr.Elem(), col, cell)
+}
+
+// Columns returns the result column names in order
+func (r *RestRows) Columns() []string {
+	return r.resp.Manifest.Schema.ColumnNames()
+}
+
+// Err returns the error, if any, that ended iteration
+func (r *RestRows) Err() error {
+	return r.err
+}
+
+// Timing returns the client-side timing of the statement's execution, with the chunk download
+// metrics of the chunks fetched so far
+func (r *RestRows) Timing() *TimingInfo {
+	r.transfer.applyTo(r.timing)
+	return r.timing
+}
+
+// Close stops iteration. With CloseAfterFetch it also releases the statement's server-side
+// result. Closing twice is a no-op.
+func (r *RestRows) Close() error {
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	r.data, r.row = nil, nil
+	if r.client.CloseAfterFetch {
+		return r.client.CloseStatement(r.ctx, r.resp.StatementID)
+	}
+	return nil
+}
+
+// nextChunk fetches and loads the chunk after the current one, reporting whether there was one
+func (r *RestRows) nextChunk() bool {
+	index := r.chunk.index()
+	if index >= r.resp.Manifest.TotalChunkCount-1 {
+		return false
+	}
+	nextLink := r.chunk.nextLink()
+	if nextLink == "" {
+		r.err = newChunkFetchError(r.resp.StatementID, index+1, fmt.Errorf("link to chunk %d of %d is missing", index+1, r.resp.Manifest.TotalChunkCount))
+		return false
+	}
+
+	chunk, err := r.client.fetchChunk(r.ctx, r.resp.StatementID, nextLink, index+1)
+	if err != nil {
+		r.err = newChunkFetchError(r.resp.StatementID, index+1, err)
+		return false
+	}
+	if err := r.load(chunk); err != nil {
+		r.err = err
+		return false
+	}
+	return true
+}
+
+// load makes chunk the current chunk, downloading its external links when present
+func (r *RestRows) load(chunk *ResultData) error {
+	r.chunk, r.pos = chunk, 0
+	if len(chunk.ExternalLinks) == 0 {
+		r.data = chunk.DataArray
+		return nil
+	}
+
+	r.data = nil
+	for _, link := range chunk.ExternalLinks {
+		linkData, err := r.client.downloadExternalLink(r.ctx, link)
+		if err != nil {
+			return newChunkFetchError(r.resp.StatementID, chunk.index(), err)
+		}
+		r.data = append(r.data, linkData...)
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"databricks-go-timing-test/testserver"
+)
+
+// newTestClient starts a mock server for the duration of the test and returns a client pointed
+// at it together with the server's handler for registering responses
+func newTestClient(t *testing.T, opts ...ClientOption) (*DatabricksRESTClient, *testserver.Handler) {
+	t.Helper()
+	srv := testserver.NewMockServer()
+	t.Cleanup(srv.Close)
+	opts = append([]ClientOption{WithHTTPClient(srv.Client())}, opts...)
+	client := NewDatabricksRESTClient(srv.Listener.Addr().String(), "token", "warehouse", opts...)
+	return client, testserver.HandlerFor(srv)
+}
+
+// TestConcurrentExecute shares one client across 100 goroutines; run with -race to check the
+// concurrency contract documented on DatabricksRESTClient
+func TestConcurrentExecute(t *testing.T) {
+	client, handler := newTestClient(t, WithCache(16), WithRetryBudget(0.1))
+
+	const workers = 100
+	for i := range workers {
+		handler.AddStatement(testserver.Statement{
+			Text: fmt.Sprintf("SELECT %d", i),
+			Rows: testserver.IntRows(i % 7),
+		})
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, workers)
+	timings := make([]*TimingInfo, workers)
+	for i := range workers {
+		wg.Go(func() {
+			timings[i], errs[i] = client.ExecuteStatementWithREST(context.Background(), fmt.Sprintf("SELECT %d", i))
+		})
+	}
+	wg.Wait()
+
+	for i := range workers {
+		if errs[i] != nil {
+			t.Fatalf("statement %d: %v", i, errs[i])
+		}
+		if timings[i].State != StateSucceeded {
+			t.Errorf("statement %d: state = %s, want %s", i, timings[i].State, StateSucceeded)
+		}
+		if want := int64(i % 7); timings[i].RowCount != want {
+			t.Errorf("statement %d: row count = %d, want %d", i, timings[i].RowCount, want)
+		}
+	}
+	if got := len(handler.Requests()); got != workers {
+		t.Errorf("server saw %d requests, want %d", got, workers)
+	}
+}
+
+func TestExecuteStatementWithREST(t *testing.T) {
+	client, handler := newTestClient(t)
+	id := handler.AddStatement(testserver.Statement{Text: "SELECT n FROM range(3)", Rows: testserver.IntRows(3)})
+
+	timing, err := client.ExecuteStatementWithREST(context.Background(), "SELECT n FROM range(3)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if timing.QueryID != id || timing.State != StateSucceeded || timing.RowCount != 3 || timing.ColumnCount != 1 {
+		t.Errorf("timing = %+v, want statement %s SUCCEEDED with 3 rows and 1 column", timing, id)
+	}
+	if timing.Method != MethodRESTAPI || timing.StatementText != "SELECT n FROM range(3)" {
+		t.Errorf("timing method/text = %s/%q", timing.Method, timing.StatementText)
+	}
+}
+
+func TestExecuteStatementWithRESTPolls(t *testing.T) {
+	client, handler := newTestClient(t)
+	id := handler.AddStatement(testserver.Statement{Text: "SELECT 1", Rows: testserver.IntRows(1), RunningPolls: 2})
+
+	// A deadline under 5s submits with wait_timeout=0s and polls until the statement finishes
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
+	defer cancel()
+	timing, err := client.ExecuteStatementWithREST(ctx, "SELECT 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if timing.State != StateSucceeded || timing.RowCount != 1 {
+		t.Errorf("timing = %+v, want SUCCEEDED with 1 row", timing)
+	}
+
+	polls := 0
+	for _, req := range handler.Requests() {
+		if req == "GET "+statementPath(id) {
+			polls++
+		}
+	}
+	// The POST answers RUNNING, as does the first poll; the second poll sees SUCCEEDED
+	if polls != 2 {
+		t.Errorf("statement polled %d times, want 2; requests: %v", polls, handler.Requests())
+	}
+}
+
+func TestExecuteStatementWithRESTFailed(t *testing.T) {
+	client, handler := newTestClient(t)
+	id := handler.AddStatement(testserver.Statement{
+		Text:  "SELEC 1",
+		Error: &testserver.StatementError{ErrorCode: "SYNTAX_ERROR", Message: "syntax error at or near 'SELEC'"},
+	})
+
+	timing, err := client.ExecuteStatementWithREST(context.Background(), "SELEC 1")
+	var statementErr *StatementError
+	if !errors.As(err, &statementErr) {
+		t.Fatalf("err = %v, want *StatementError", err)
+	}
+	if statementErr.StatementID != id || statementErr.ErrorCode != "SYNTAX_ERROR" {
+		t.Errorf("statement error = %+v", statementErr)
+	}
+	if timing == nil || timing.State != StateFailed || !strings.HasPrefix(timing.ErrorMessage, "SYNTAX_ERROR: ") {
+		t.Errorf("timing = %+v, want FAILED with the error summary", timing)
+	}
+}
+
+func TestExecuteStatementWithRESTUnknownStatement(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	_, err := client.ExecuteStatementWithREST(context.Background(), "SELECT missing")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest || apiErr.ErrorCode != "INVALID_PARAMETER_VALUE" {
+		t.Errorf("err = %v, want a 400 INVALID_PARAMETER_VALUE *APIError", err)
+	}
+}
+
+func TestExecuteStatementWithRESTRetries(t *testing.T) {
+	client, handler := newTestClient(t)
+	client.BaseBackoff = time.Millisecond
+	handler.AddStatement(testserver.Statement{Text: "SELECT 1", Rows: testserver.IntRows(1)})
+	handler.FailRequests(http.StatusServiceUnavailable, http.StatusTooManyRequests)
+
+	timing, err := client.ExecuteStatementWithREST(context.Background(), "SELECT 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if timing.Retries != 2 {
+		t.Errorf("retries = %d, want 2", timing.Retries)
+	}
+}
+
+func TestGetStatementTiming(t *testing.T) {
+	client, handler := newTestClient(t)
+	id := handler.AddStatement(testserver.Statement{Text: "SELECT 1", Rows: testserver.IntRows(1)})
+	failedID := handler.AddStatement(testserver.Statement{Text: "SELECT 1/0", Error: &testserver.StatementError{ErrorCode: "DIVIDE_BY_ZERO", Message: "division by zero"}})
+	for _, text := range []string{"SELECT 1", "SELECT 1/0"} {
+		client.ExecuteStatementWithREST(context.Background(), text)
+	}
+
+	timing, err := client.GetStatementTiming(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if timing.QueryID != id || timing.State != StateSucceeded || timing.RowCount != 1 {
+		t.Errorf("timing = %+v, want statement %s SUCCEEDED with 1 row", timing, id)
+	}
+
+	timing, err = client.GetStatementTiming(context.Background(), failedID)
+	var statementErr *StatementError
+	if !errors.As(err, &statementErr) || statementErr.ErrorCode != "DIVIDE_BY_ZERO" {
+		t.Errorf("err = %v, want DIVIDE_BY_ZERO *StatementError", err)
+	}
+	if timing == nil || timing.State != StateFailed {
+		t.Errorf("timing = %+v, want FAILED", timing)
+	}
+
+	_, err = client.GetStatementTiming(context.Background(), "00000000-0000-0000-0000-999999999999")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("err = %v, want a 404 *APIError", err)
+	}
+}
+
+func TestExecuteAndFetchRowsFollowsChunks(t *testing.T) {
+	client, handler := newTestClient(t)
+	handler.AddStatement(testserver.Statement{Text: "SELECT n FROM range(10)", Rows: testserver.IntRows(10), ChunkSize: 3})
+
+	rows, timing, err := client.ExecuteAndFetchRows(context.Background(), "SELECT n FROM range(10)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 10 || timing.RowCount != 10 {
+		t.Fatalf("got %d rows (timing %d), want 10", len(rows), timing.RowCount)
+	}
+	for i, row := range rows {
+		if row[0] != int64(i) {
+			t.Errorf("row %d = %v, want %d", i, row[0], i)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultMaxRetries  = 3
+	defaultBaseBackoff = 500 * time.Millisecond
+	maxBackoff         = 30 * time.Second
+)
+
+// shouldRetry reports whether a failed response may be retried. A POST is only retried while
+// the server has not handed back a statement ID, so a created statement is never submitted twice.
+func shouldRetry(method string, apiErr *APIError, body []byte) bool {
+	if !apiErr.IsRetryable() {
+		return false
+	}
+
+	if method == http.MethodPost {
+		var partial struct {
+			StatementID string `json:"statement_id"`
+		}
+		if json.Unmarshal(body, &partial) == nil && partial.StatementID != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// retryDelay honors a Retry-After header when present, otherwise uses full-jitter
+// exponential backoff: a random delay between 0 and BaseBackoff * 2^attempt.
+func (c *DatabricksRESTClient) retryDelay(attempt int, header http.Header) time.Duration {
+	if delay, ok := parseRetryAfter(header.Get("Retry-After")); ok {
+		return delay
+	}
+
+	base := c.BaseBackoff
+	if base <= 0 {
+		base = defaultBaseBackoff
+	}
+	ceiling := maxBackoff
+	if attempt < 16 {
+		ceiling = min(base<<attempt, maxBackoff)
+	}
+	return rand.N(ceiling + 1)
+}
+
+// parseRetryAfter accepts both the delay-seconds and HTTP-date forms of Retry-After
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
+}
+
+// sleepContext waits for d or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
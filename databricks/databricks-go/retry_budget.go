@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// retryBudgetMax caps the budget so a long healthy stretch can't bank an unbounded burst of
+// retries for the next outage. A fresh budget starts full.
+const retryBudgetMax = 10
+
+// retryBudget is a token bucket shared by every request of a client. Each successful request
+// deposits ratio tokens and each retry withdraws one, so during an outage retries stop once the
+// bucket is empty instead of multiplying the load on a struggling workspace.
+type retryBudget struct {
+	mu     sync.Mutex
+	ratio  float64
+	tokens float64
+}
+
+func newRetryBudget(ratio float64) *retryBudget {
+	return &retryBudget{ratio: ratio, tokens: retryBudgetMax}
+}
+
+// success refills the budget after a successful request
+func (b *retryBudget) success() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = min(b.tokens+b.ratio, retryBudgetMax)
+}
+
+// allowRetry withdraws a token and reports whether a retry may be made. A nil budget always
+// allows it.
+func (b *retryBudget) allowRetry() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// allowRetry consults the client's retry budget, logging when it denies a retry
+func (c *DatabricksRESTClient) allowRetry(ctx context.Context, path string) bool {
+	if c.retryBudget.allowRetry() {
+		return true
+	}
+	c.logger.DebugContext(ctx, "retry budget exhausted, not retrying", slog.String("path", path))
+	return false
+}
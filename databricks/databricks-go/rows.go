@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// TypeConverter converts a JSON_ARRAY cell to a Go value for its manifest column. cell is nil
+// for NULL. Columns beyond the manifest schema are passed as a zero Column.
+type TypeConverter func(col Column, cell *string) (any, error)
+
+// DefaultTypeConverter maps cells by type_name: integer types to int64, FLOAT, DOUBLE and DECIMAL
+// to float64, BOOLEAN to bool, DATE and TIMESTAMP to time.Time, and anything else to string.
+// Wrap it to override single types; ExactDecimalConverter keeps DECIMAL exact.
+func DefaultTypeConverter(col Column, cell *string) (any, error) {
+	return convertValue(col.TypeName, cell)
+}
+
+// typeConverter returns the client's Converter, or DefaultTypeConverter when none is set
+func (c *DatabricksRESTClient) typeConverter() TypeConverter {
+	if c.Converter == nil {
+		return DefaultTypeConverter
+	}
+	return c.Converter
+}
+
+// decodeRows converts a JSON_ARRAY data_array into Go values using the manifest column types.
+// A nil data_array (zero-row INLINE result) decodes to an empty slice.
+func decodeRows(schema Schema, data [][]*string, convert TypeConverter) ([][]any, error) {
+	rows := make([][]any, 0, len(data))
+	for i, raw := range data {
+		row := make([]any, len(raw))
+		for j, cell := range raw {
+			var col Column
+			if j < len(schema.Columns) {
+				col = schema.Columns[j]
+			}
+			value, err := convert(col, cell)
+			if err != nil {
+				return nil, fmt.Errorf("row %d, column %d: %w", i, j, err)
+			}
+			row[j] = value
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// convertValue maps a single JSON_ARRAY cell to a Go value based on its Databricks type name.
+// Types without a natural Go mapping are returned as strings.
+func convertValue(typeName string, cell *string) (any, error) {
+	if cell == nil {
+		return nil, nil
+	}
+	value := *cell
+
+	switch typeName {
+	case "LONG", "BIGINT", "INT", "INTEGER", "SHORT", "SMALLINT", "BYTE", "TINYINT":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value %q: %w", typeName, value, err)
+		}
+		return n, nil
+	case "DOUBLE", "FLOAT", "DECIMAL":
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value %q: %w", typeName, value, err)
+		}
+		return f, nil
+	case "BOOLEAN":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value %q: %w", typeName, value, err)
+		}
+		return b, nil
+	case "DATE":
+		t, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value %q: %w", typeName, value, err)
+		}
+		return t, nil
+	case "TIMESTAMP", "TIMESTAMP_NTZ":
+		t, err := parseTimestamp(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value: %w", typeName, err)
+		}
+		return t, nil
+	default:
+		return value, nil
+	}
+}
+
+// namedRow gives name-based access to a decoded row using the manifest column names
+type namedRow struct {
+	index map[string]int
+	row   []any
+}
+
+// columnIndexes maps each column name in the schema to its position
+func columnIndexes(schema Schema) map[string]int {
+	index := make(map[string]int, len(schema.Columns))
+	for i, col := range schema.Columns {
+		index[col.Name] = i
+	}
+	return index
+}
+
+func (r namedRow) value(name string) any {
+	i, ok := r.index[name]
+	if !ok || i >= len(r.row) {
+		return nil
+	}
+	return r.row[i]
+}
+
+// String returns the column as a string, or "" when it is NULL or absent
+func (r namedRow) String(name string) string {
+	switch v := r.value(name).(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Int64 returns an integer column, or 0 when it is NULL or absent
+func (r namedRow) Int64(name string) (int64, error) {
+	switch v := r.value(name).(type) {
+	case nil:
+		return 0, nil
+	case int64:
+		return v, nil
+	case float64:
+		return int64(v), nil
+	case string:
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("column %s: invalid integer %q", name, v)
+		}
+		return n, nil
+	default:
+		return 0, fmt.Errorf("column %s: unexpected type %T", name, v)
+	}
+}
+
+// Bool returns a BOOLEAN column, or false when it is NULL or absent
+func (r namedRow) Bool(name string) (bool, error) {
+	switch v := r.value(name).(type) {
+	case nil:
+		return false, nil
+	case bool:
+		return v, nil
+	case string:
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return false, fmt.Errorf("column %s: invalid boolean %q", name, v)
+		}
+		return b, nil
+	default:
+		return false, fmt.Errorf("column %s: unexpected type %T", name, v)
+	}
+}
+
+// Time returns a TIMESTAMP column, or the zero time when it is NULL or absent
+func (r namedRow) Time(name string) (time.Time, error) {
+	if t, ok := r.value(name).(time.Time); ok {
+		return t, nil
+	}
+	s := r.String(name)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	t, err := parseTimestamp(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("column %s: %w", name, err)
+	}
+	return t, nil
+}
+
+// timestampLayouts are the TIMESTAMP renderings returned in JSON_ARRAY results
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+}
+
+func parseTimestamp(s string) (time.Time, error) {
+	for _, layout := range timestampLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp %q", s)
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDefaultTypeConverter(t *testing.T) {
+	str := func(s string) *string { return &s }
+
+	tests := []struct {
+		typeName string
+		cell     *string
+		want     any
+		wantErr  bool
+	}{
+		{typeName: "BOOLEAN", cell: str("true"), want: true},
+		{typeName: "BOOLEAN", cell: str("false"), want: false},
+		{typeName: "BOOLEAN", cell: str("yes"), wantErr: true},
+		{typeName: "TINYINT", cell: str("-128"), want: int64(-128)},
+		{typeName: "BYTE", cell: str("127"), want: int64(127)},
+		{typeName: "SMALLINT", cell: str("-32768"), want: int64(-32768)},
+		{typeName: "SHORT", cell: str("32767"), want: int64(32767)},
+		{typeName: "INT", cell: str("2147483647"), want: int64(2147483647)},
+		{typeName: "INTEGER", cell: str("-2147483648"), want: int64(-2147483648)},
+		{typeName: "BIGINT", cell: str("9223372036854775807"), want: int64(9223372036854775807)},
+		{typeName: "LONG", cell: str("-9223372036854775808"), want: int64(-9223372036854775808)},
+		{typeName: "BIGINT", cell: str("1.5"), wantErr: true},
+		{typeName: "FLOAT", cell: str("1.5"), want: 1.5},
+		{typeName: "DOUBLE", cell: str("-2.25E10"), want: -2.25e10},
+		{typeName: "DOUBLE", cell: str("Infinity"), want: math.Inf(1)},
+		{typeName: "DOUBLE", cell: str("-Infinity"), want: math.Inf(-1)},
+		{typeName: "DOUBLE", cell: str("abc"), wantErr: true},
+		{typeName: "DECIMAL", cell: str("12345.678"), want: 12345.678},
+		{typeName: "STRING", cell: str("hello, world"), want: "hello, world"},
+		{typeName: "STRING", cell: str(""), want: ""},
+		// BINARY is returned base64-encoded and left for the caller to decode
+		{typeName: "BINARY", cell: str("AQID"), want: "AQID"},
+		{typeName: "DATE", cell: str("2024-02-29"), want: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{typeName: "DATE", cell: str("2024-02-30"), wantErr: true},
+		{typeName: "TIMESTAMP", cell: str("2025-09-04T02:42:26.690184Z"), want: time.Date(2025, 9, 4, 2, 42, 26, 690184000, time.UTC)},
+		{typeName: "TIMESTAMP", cell: str("2025-09-04T04:42:26+02:00"), want: time.Date(2025, 9, 4, 2, 42, 26, 0, time.UTC)},
+		{typeName: "TIMESTAMP", cell: str("not a time"), wantErr: true},
+		{typeName: "TIMESTAMP_NTZ", cell: str("2025-09-04T02:42:26.5"), want: time.Date(2025, 9, 4, 2, 42, 26, 5e8, time.UTC)},
+		{typeName: "TIMESTAMP_NTZ", cell: str("2025-09-04 02:42:26"), want: time.Date(2025, 9, 4, 2, 42, 26, 0, time.UTC)},
+		// Types without a natural Go mapping keep their JSON_ARRAY text
+		{typeName: "INTERVAL", cell: str("1 02:03:04.000000000"), want: "1 02:03:04.000000000"},
+		{typeName: "ARRAY", cell: str(`[1,2,3]`), want: `[1,2,3]`},
+		{typeName: "MAP", cell: str(`{"a":1}`), want: `{"a":1}`},
+		{typeName: "STRUCT", cell: str(`{"x":1,"y":"z"}`), want: `{"x":1,"y":"z"}`},
+		{typeName: "VOID", cell: str("unknown"), want: "unknown"},
+		{typeName: "", cell: str("no column"), want: "no column"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.typeName+"/"+*tt.cell, func(t *testing.T) {
+			got, err := DefaultTypeConverter(Column{TypeName: tt.typeName}, tt.cell)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %#v, want an error", got)
+				}
+				if !strings.Contains(err.Error(), tt.typeName) {
+					t.Errorf("error %q does not name the type", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want, ok := tt.want.(time.Time); ok {
+				if got, ok := got.(time.Time); !ok || !got.Equal(want) {
+					t.Errorf("got %#v, want %v", got, want)
+				}
+				return
+			}
+			if got != tt.want {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultTypeConverterNaN(t *testing.T) {
+	nan := "NaN"
+	got, err := DefaultTypeConverter(Column{TypeName: "DOUBLE"}, &nan)
+	if f, ok := got.(float64); err != nil || !ok || !math.IsNaN(f) {
+		t.Errorf("DOUBLE NaN = %#v, %v, want NaN", got, err)
+	}
+}
+
+func TestDefaultTypeConverterNull(t *testing.T) {
+	for _, typeName := range []string{
+		"BOOLEAN", "TINYINT", "SMALLINT", "INT", "BIGINT", "FLOAT", "DOUBLE", "DECIMAL",
+		"STRING", "BINARY", "DATE", "TIMESTAMP", "TIMESTAMP_NTZ", "INTERVAL", "ARRAY", "MAP", "STRUCT",
+		"NULL", "UNKNOWN_TYPE",
+	} {
+		got, err := DefaultTypeConverter(Column{TypeName: typeName}, nil)
+		if err != nil || got != nil {
+			t.Errorf("%s NULL = %#v, %v, want nil", typeName, got, err)
+		}
+	}
+}
+
+func TestDecodeRows(t *testing.T) {
+	str := func(s string) *string { return &s }
+	schema := Schema{ColumnCount: 2, Columns: []Column{{Name: "id", TypeName: "BIGINT"}, {Name: "name", TypeName: "STRING"}}}
+
+	rows, err := decodeRows(schema, [][]*string{
+		{str("1"), str("a")},
+		{str("2"), nil},
+		// Cells beyond the schema get a zero Column and decode as strings
+		{str("3"), str("c"), str("extra")},
+	}, DefaultTypeConverter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]any{{int64(1), "a"}, {int64(2), nil}, {int64(3), "c", "extra"}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %#v, want %#v", rows, want)
+	}
+
+	if rows, err := decodeRows(schema, nil, DefaultTypeConverter); err != nil || rows == nil || len(rows) != 0 {
+		t.Errorf("nil data_array = %#v, %v, want an empty slice", rows, err)
+	}
+
+	_, err = decodeRows(schema, [][]*string{{str("1"), str("a")}, {str("x"), str("b")}}, DefaultTypeConverter)
+	if err == nil || !strings.HasPrefix(err.Error(), "row 1, column 0: ") {
+		t.Errorf("err = %v, want it to locate row 1, column 0", err)
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// ScanRows decodes the rows held in resp.Result into dest, which must be a pointer to a slice of
+// structs (or struct pointers). Fields are matched to columns by name using a
+// `databricks:"column_name"` tag; untagged fields are ignored. A tagged field whose column is not
+// in the manifest is an error unless the tag includes ",optional". Supported field types are
+// strings, integers, floats, bools, time.Time, big.Rat, Decimal, and pointers to those (nil for
+// SQL NULL). DECIMAL columns keep every digit in a string, big.Rat or Decimal field; a float
+// field is allowed but rounds to float64's roughly 15 significant digits.
+func ScanRows(resp *StatementExecutionResponse, dest interface{}) error {
+	slicePtr := reflect.ValueOf(dest)
+	if slicePtr.Kind() != reflect.Pointer || slicePtr.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("ScanRows: dest must be a pointer to a slice, got %T", dest)
+	}
+	slice := slicePtr.Elem()
+
+	elemType := slice.Type().Elem()
+	structType := elemType
+	if elemType.Kind() == reflect.Pointer {
+		structType = elemType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return fmt.Errorf("ScanRows: slice element must be a struct, got %s", elemType)
+	}
+
+	fields, err := mapTaggedFields(structType, columnIndexes(resp.Manifest.Schema))
+	if err != nil {
+		return err
+	}
+
+	columns := resp.Manifest.Schema.Columns
+	rows := reflect.MakeSlice(slice.Type(), 0, len(resp.Result.DataArray))
+	for i, raw := range resp.Result.DataArray {
+		item := reflect.New(structType).Elem()
+		for _, f := range fields {
+			var cell *string
+			if f.column < len(raw) {
+				cell = raw[f.column]
+			}
+			var col Column
+			if f.column < len(columns) {
+				col = columns[f.column]
+			}
+			if err := assignCell(item.Field(f.field), col, cell); err != nil {
+				return fmt.Errorf("ScanRows: row %d, column %s: %w", i, f.name, err)
+			}
+		}
+
+		if elemType.Kind() == reflect.Pointer {
+			rows = reflect.Append(rows, item.Addr())
+		} else {
+			rows = reflect.Append(rows, item)
+		}
+	}
+	slice.Set(rows)
+	return nil
+}
+
+// taggedField links a struct field index to its result column position
+type taggedField struct {
+	name   string
+	field  int
+	column int
+}
+
+func mapTaggedFields(structType reflect.Type, columns map[string]int) ([]taggedField, error) {
+	var fields []taggedField
+	for i := 0; i < structType.NumField(); i++ {
+		tag, ok := structType.Field(i).Tag.Lookup("databricks")
+		if !ok || tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		column, found := columns[name]
+		if !found {
+			if opts == "optional" {
+				continue
+			}
+			return nil, fmt.Errorf("ScanRows: field %s.%s: column %q not found in result manifest", structType.Name(), structType.Field(i).Name, name)
+		}
+		fields = append(fields, taggedField{name: name, field: i, column: column})
+	}
+	return fields, nil
+}
+
+// assignCell converts a JSON_ARRAY cell of col into the field's type. NULL leaves the zero value.
+func assignCell(field reflect.Value, col Column, cell *string) error {
+	if cell == nil {
+		field.SetZero()
+		return nil
+	}
+	if field.Kind() == reflect.Pointer {
+		ptr := reflect.New(field.Type().Elem())
+		if err := assignCell(ptr.Elem(), col, cell); err != nil {
+			return err
+		}
+		field.Set(ptr)
+		return nil
+	}
+
+	value := *cell
+	if ok, err := assignDecimal(field, col, value); ok {
+		return err
+	}
+	if field.Type() == timeType {
+		t, err := parseTimestamp(value)
+		if err != nil {
+			// DATE columns render without a time component
+			if t, err = time.Parse(time.DateOnly, value); err != nil {
+				return fmt.Errorf("invalid time %q", value)
+			}
+		}
+		field.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid unsigned integer %q", value)
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid float %q", value)
+		}
+		field.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid bool %q", value)
+		}
+		field.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
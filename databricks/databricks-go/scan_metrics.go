@@ -0,0 +1,45 @@
+package main
+
+// ScanMetrics reports how much of a query's input was read versus skipped by file and
+// partition pruning
+type ScanMetrics struct {
+	ReadFiles      int64
+	PrunedFiles    int64
+	ReadPartitions int64
+	// PrunedBytes is only reported by the history endpoint; it is zero for system.query.history
+	PrunedBytes int64
+}
+
+// PruningEffectiveness returns the fraction of candidate files that pruning skipped, from 0 (every
+// file read) to 1. It returns 0 when the query touched no files.
+func (s ScanMetrics) PruningEffectiveness() float64 {
+	total := s.ReadFiles + s.PrunedFiles
+	if total == 0 {
+		return 0
+	}
+	return float64(s.PrunedFiles) / float64(total)
+}
+
+// ScanMetrics returns the file and partition counters from system.query.history
+func (q QueryHistoryResponse) ScanMetrics() ScanMetrics {
+	return ScanMetrics{
+		ReadFiles:      q.ReadFiles,
+		PrunedFiles:    q.PrunedFiles,
+		ReadPartitions: q.ReadPartitions,
+	}
+}
+
+// ScanMetrics returns the file and partition counters from the history endpoint's metrics.
+// A response without metrics yields empty ScanMetrics.
+func (h HistoryQueryResponse) ScanMetrics() ScanMetrics {
+	m := h.Metrics
+	if m == nil {
+		return ScanMetrics{}
+	}
+	return ScanMetrics{
+		ReadFiles:      m.ReadFilesCount,
+		PrunedFiles:    m.PrunedFilesCount,
+		ReadPartitions: m.ReadPartitionsCount,
+		PrunedBytes:    m.PrunedBytes,
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+// Schema lists the columns of a result set, as reported in the statement manifest
+type Schema struct {
+	ColumnCount int      `json:"column_count"`
+	Columns     []Column `json:"columns"`
+}
+
+// Column describes a single result column. TypeName is the base type (e.g. DECIMAL) while
+// TypeText is the full SQL type (e.g. DECIMAL(38,18)).
+type Column struct {
+	Name          string `json:"name"`
+	TypeName      string `json:"type_name"`
+	TypeText      string `json:"type_text,omitempty"`
+	TypePrecision int    `json:"type_precision,omitempty"`
+	TypeScale     int    `json:"type_scale,omitempty"`
+	Position      int    `json:"position"`
+}
+
+// ColumnIndex returns the index of the named column in Columns
+func (s Schema) ColumnIndex(name string) (int, bool) {
+	for i, col := range s.Columns {
+		if col.Name == name {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// ColumnNames returns the column names in result order
+func (s Schema) ColumnNames() []string {
+	names := make([]string, len(s.Columns))
+	for i, col := range s.Columns {
+		names[i] = col.Name
+	}
+	return names
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrSessionsUnsupported is returned by OpenSession when the workspace or warehouse does not
+// expose the SQL sessions API
+var ErrSessionsUnsupported = errors.New("SQL sessions are not supported by this warehouse")
+
+// OpenSession creates a server-side SQL session on the client's warehouse. Statements run with
+// ExecuteInSession share its state, so temporary views, SET values and BEGIN/COMMIT
+// transactions persist across calls. Close it with CloseSession.
+//
+// Sessions are served by /api/2.0/sql/sessions, which is only available for SQL warehouses on
+// workspaces where the Statement Execution API supports sessions (serverless and pro warehouses
+// on current channels). Elsewhere the endpoint answers 404 or 501 and ErrSessionsUnsupported is
+// returned.
+func (c *DatabricksRESTClient) OpenSession(ctx context.Context) (string, error) {
+	reqBody := struct {
+		WarehouseID string `json:"warehouse_id"`
+	}{c.warehouseID}
+	var resp struct {
+		SessionID string `json:"session_id"`
+	}
+
+	err := c.doJSON(ctx, "POST", "/api/2.0/sql/sessions", reqBody, &resp)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusNotFound || apiErr.StatusCode == http.StatusNotImplemented) {
+		return "", fmt.Errorf("failed to open session on warehouse %s: %w: %w", c.warehouseID, ErrSessionsUnsupported, err)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to open session on warehouse %s: %w", c.warehouseID, err)
+	}
+	if resp.SessionID == "" {
+		return "", fmt.Errorf("failed to open session on warehouse %s: response has no session_id", c.warehouseID)
+	}
+	return resp.SessionID, nil
+}
+
+// ExecuteInSession runs a statement in a session opened with OpenSession
+func (c *DatabricksRESTClient) ExecuteInSession(ctx context.Context, sessionID, statement string) (*TimingInfo, error) {
+	if sessionID == "" {
+		return nil, errors.New("session ID is empty")
+	}
+	reqBody := c.newStatementRequest(statement)
+	reqBody.SessionID = sessionID
+	timing, _, err := c.executeStatement(ctx, reqBody)
+	return timing, err
+}
+
+// CloseSession ends a session, discarding its temporary objects and rolling back any open
+// transaction. Closing a session that no longer exists is not an error.
+func (c *DatabricksRESTClient) CloseSession(ctx context.Context, sessionID string) error {
+	err := c.doJSON(ctx, "DELETE", "/api/2.0/sql/sessions/"+sessionID+"?warehouse_id="+c.warehouseID, nil, nil)
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to close session %s: %w", sessionID, err)
+	}
+	return nil
+}
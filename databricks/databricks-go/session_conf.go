@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"regexp"
+	"slices"
+)
+
+// ErrSessionConfNotSet is returned by GetSessionConf for a key with no value, which SET reports
+// as <undefined>
+var ErrSessionConfNotSet = errors.New("session conf not set")
+
+var (
+	// sessionConfKey matches Spark and SQL conf names such as spark.databricks.delta.optimize.maxFileSize
+	sessionConfKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.\-]*$`)
+	// sessionConfValue matches the conf values SET takes unquoted: booleans, numbers, sizes such
+	// as 128mb, enum names and time zones such as America/New_York or +02:00
+	sessionConfValue = regexp.MustCompile(`^[A-Za-z0-9_.:/+\-]+$`)
+)
+
+// setStatement renders SET key = value. SET takes the rest of the statement as the value, so the
+// value is limited to a conservative charset rather than quoted.
+func setStatement(key, value string) (string, error) {
+	if !sessionConfKey.MatchString(key) {
+		return "", fmt.Errorf("invalid session conf key %q", key)
+	}
+	if !sessionConfValue.MatchString(value) {
+		return "", fmt.Errorf("invalid value for session conf %s: %q", key, value)
+	}
+	return fmt.Sprintf("SET %s = %s", key, value), nil
+}
+
+// openConfiguredSession opens a session and applies confs to it with SET, in key order. The
+// returned func closes the session on a detached context, so it also runs after ctx is done.
+func (c *DatabricksRESTClient) openConfiguredSession(ctx context.Context, confs map[string]string) (string, func(), error) {
+	statements := make([]string, 0, len(confs))
+	for _, key := range slices.Sorted(maps.Keys(confs)) {
+		stmt, err := setStatement(key, confs[key])
+		if err != nil {
+			return "", nil, err
+		}
+		statements = append(statements, stmt)
+	}
+
+	sessionID, err := c.OpenSession(ctx)
+	if err != nil {
+		return "", nil, fmt.Errorf("session confs need a session: %w", err)
+	}
+	closeSession := func() {
+		closeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cancelTimeout)
+		defer cancel()
+		if err := c.CloseSession(closeCtx, sessionID); err != nil {
+			c.logger.WarnContext(ctx, "failed to close session confs session; it stays open until it expires",
+				slog.String("session_id", sessionID),
+				slog.String("error", err.Error()))
+		}
+	}
+	for _, stmt := range statements {
+		if _, err := c.ExecuteInSession(ctx, sessionID, stmt); err != nil {
+			closeSession()
+			return "", nil, fmt.Errorf("failed to apply session conf: %w", err)
+		}
+	}
+	return sessionID, closeSession, nil
+}
+
+// GetSessionConf reads the current value of a Spark or SQL conf with SET <key>. Outside a
+// session this is the warehouse's default for new statements. A key with no value returns
+// ErrSessionConfNotSet.
+func (c *DatabricksRESTClient) GetSessionConf(ctx context.Context, key string) (string, error) {
+	if !sessionConfKey.MatchString(key) {
+		return "", fmt.Errorf("invalid session conf key %q", key)
+	}
+	schema, rows, err := c.query(ctx, "SET "+key)
+	if err != nil {
+		return "", fmt.Errorf("failed to read session conf %s: %w", key, err)
+	}
+
+	index := columnIndexes(schema)
+	for _, row := range rows {
+		r := namedRow{index: index, row: row}
+		if r.String("key") != key {
+			continue
+		}
+		value := r.String("value")
+		if value == "<undefined>" {
+			return "", fmt.Errorf("%s: %w", key, ErrSessionConfNotSet)
+		}
+		return value, nil
+	}
+	return "", fmt.Errorf("%s: %w", key, ErrSessionConfNotSet)
+}
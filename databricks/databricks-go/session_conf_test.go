@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"databricks-go-timing-test/testserver"
+)
+
+func TestSetStatement(t *testing.T) {
+	tests := []struct {
+		key, value string
+		want       string
+	}{
+		{"use_cached_result", "false", "SET use_cached_result = false"},
+		{"spark.databricks.delta.optimize.maxFileSize", "128mb", "SET spark.databricks.delta.optimize.maxFileSize = 128mb"},
+		{"spark.sql.shuffle.partitions", "-1", "SET spark.sql.shuffle.partitions = -1"},
+		{"TIME_ZONE", "America/New_York", "SET TIME_ZONE = America/New_York"},
+		{"TIME_ZONE", "+02:00", "SET TIME_ZONE = +02:00"},
+		{"ansi_mode", "true; DROP TABLE t", ""},
+		{"ansi_mode", "true -- comment", ""},
+		{"ansi_mode", "'quoted'", ""},
+		{"ansi_mode", "a\nb", ""},
+		{"ansi_mode", "", ""},
+		{"bad key", "true", ""},
+		{"key=1", "true", ""},
+	}
+	for _, tt := range tests {
+		got, err := setStatement(tt.key, tt.value)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("setStatement(%q, %q) = %q, want an error", tt.key, tt.value, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("setStatement(%q, %q) = %q, %v, want %q", tt.key, tt.value, got, err, tt.want)
+		}
+	}
+}
+
+func TestExecuteWithSessionConfs(t *testing.T) {
+	client, handler := newTestClient(t)
+	for _, text := range []string{"SET ansi_mode = true", "SET use_cached_result = false", "SELECT 1"} {
+		handler.AddStatement(testserver.Statement{Text: text, Rows: testserver.IntRows(1)})
+	}
+
+	_, err := client.ExecuteStatementWithOptions(context.Background(), "SELECT 1", ExecOptions{
+		SessionConfs: map[string]string{"use_cached_result": "false", "ansi_mode": "true"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	executions := handler.Executions()
+	var texts []string
+	for _, e := range executions {
+		texts = append(texts, e.Text)
+		if e.SessionID == "" || e.SessionID != executions[0].SessionID {
+			t.Errorf("%q ran in session %q, want every statement in session %q", e.Text, e.SessionID, executions[0].SessionID)
+		}
+	}
+	// Confs are applied in key order before the statement
+	if want := []string{"SET ansi_mode = true", "SET use_cached_result = false", "SELECT 1"}; !slices.Equal(texts, want) {
+		t.Errorf("executed %q, want %q", texts, want)
+	}
+	if open := handler.OpenSessions(); len(open) != 0 {
+		t.Errorf("sessions left open: %v", open)
+	}
+}
+
+func TestExecuteWithInvalidSessionConf(t *testing.T) {
+	client, handler := newTestClient(t)
+
+	_, err := client.ExecuteStatementWithOptions(context.Background(), "SELECT 1", ExecOptions{
+		SessionConfs: map[string]string{"ansi_mode": "true; DROP TABLE t"},
+	})
+	if err == nil {
+		t.Fatal("invalid conf value was accepted")
+	}
+	// Validation happens before any session is opened
+	if reqs := handler.Requests(); len(reqs) != 0 {
+		t.Errorf("requests sent for an invalid conf: %v", reqs)
+	}
+}
+
+func TestSessionConfsCloseFailureIsLogged(t *testing.T) {
+	var logs bytes.Buffer
+	srv := testserver.NewMockServer()
+	defer srv.Close()
+	handler := testserver.HandlerFor(srv)
+	handler.AddStatement(testserver.Statement{Text: "SET ansi_mode = true"})
+	handler.AddStatement(testserver.Statement{Text: "SELECT 1", Rows: testserver.IntRows(1)})
+
+	next := srv.Client().Transport
+	failClose := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Method == http.MethodDelete && strings.HasPrefix(req.URL.Path, "/api/2.0/sql/sessions/") {
+			return &http.Response{
+				StatusCode: http.StatusBadRequest,
+				Body:       http.NoBody,
+				Request:    req,
+			}, nil
+		}
+		return next.RoundTrip(req)
+	})
+	client := NewDatabricksRESTClient(srv.Listener.Addr().String(), "token", "warehouse",
+		WithHTTPClient(srv.Client()), WithTransport(failClose), WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+
+	_, err := client.ExecuteStatementWithOptions(context.Background(), "SELECT 1", ExecOptions{
+		SessionConfs: map[string]string{"ansi_mode": "true"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	open := handler.OpenSessions()
+	if len(open) != 1 {
+		t.Fatalf("open sessions = %v, want the one whose close failed", open)
+	}
+	if out := logs.String(); !strings.Contains(out, "failed to close session confs session") || !strings.Contains(out, open[0]) {
+		t.Errorf("failed close was not logged; logs:\n%s", out)
+	}
+}
+
+func TestGetSessionConf(t *testing.T) {
+	client, handler := newTestClient(t)
+	str := func(s string) *string { return &s }
+	columns := []testserver.Column{{Name: "key", TypeName: "STRING"}, {Name: "value", TypeName: "STRING"}}
+	handler.AddStatement(testserver.Statement{
+		Text:    "SET spark.sql.shuffle.partitions",
+		Columns: columns,
+		Rows:    [][]*string{{str("spark.sql.shuffle.partitions"), str("200")}},
+	})
+	handler.AddStatement(testserver.Statement{
+		Text:    "SET spark.unset.conf",
+		Columns: columns,
+		Rows:    [][]*string{{str("spark.unset.conf"), str("<undefined>")}},
+	})
+
+	value, err := client.GetSessionConf(context.Background(), "spark.sql.shuffle.partitions")
+	if err != nil || value != "200" {
+		t.Errorf("GetSessionConf = %q, %v, want 200", value, err)
+	}
+	if _, err := client.GetSessionConf(context.Background(), "spark.unset.conf"); !errors.Is(err, ErrSessionConfNotSet) {
+		t.Errorf("unset conf err = %v, want ErrSessionConfNotSet", err)
+	}
+	if _, err := client.GetSessionConf(context.Background(), "spark.sql; DROP TABLE t"); err == nil {
+		t.Error("invalid conf key was accepted")
+	}
+}
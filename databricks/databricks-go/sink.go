@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// TimingSink receives timing records as they are produced, so long-running collectors do not
+// have to hold them in memory. Record may buffer; Flush makes buffered records durable.
+type TimingSink interface {
+	Record(TimingInfo) error
+	Flush() error
+}
+
+// defaultSinkFlushInterval is how often a BufferedSink flushes when no interval is given
+const defaultSinkFlushInterval = 5 * time.Second
+
+// BufferedSink is a TimingSink that encodes records into a buffer and writes them out every flush
+// interval and on Close, rather than syncing per record. It is safe for concurrent use.
+type BufferedSink struct {
+	mu     sync.Mutex
+	w      *bufio.Writer
+	encode func(*bufio.Writer, TimingInfo) error
+	// file is synced on flush and closed on Close; nil for stdout
+	file *os.File
+	// err is the first error from a background flush, reported by the next call
+	err error
+
+	stop chan struct{}
+	done chan struct{}
+
+	closeOnce sync.Once
+	// closeErr is the result of the first Close, returned again by later calls
+	closeErr error
+}
+
+// NewCSVSink appends records to a CSV file at path with the same columns as
+// TimingComparison.WriteCSV, writing the header when the file is new or empty. A flushInterval of
+// zero flushes every 5s.
+func NewCSVSink(path string, flushInterval time.Duration) (*BufferedSink, error) {
+	file, err := openSinkFile(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to stat sink file %s: %w", path, err)
+	}
+
+	s := newBufferedSink(file, file, flushInterval, func(w *bufio.Writer, t TimingInfo) error {
+		cw := csv.NewWriter(w)
+		cw.Write(timingCSVRecord(t))
+		cw.Flush()
+		return cw.Error()
+	})
+	if info.Size() == 0 {
+		cw := csv.NewWriter(s.w)
+		cw.Write(timingCSVHeader)
+		cw.Flush()
+	}
+	return s, nil
+}
+
+// NewJSONLSink appends records to a file at path as one JSON object per line. A flushInterval of
+// zero flushes every 5s.
+func NewJSONLSink(path string, flushInterval time.Duration) (*BufferedSink, error) {
+	file, err := openSinkFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return newBufferedSink(file, file, flushInterval, encodeTimingJSON), nil
+}
+
+// NewStdoutSink writes records to stdout as JSON lines. Close flushes but leaves stdout open.
+func NewStdoutSink(flushInterval time.Duration) *BufferedSink {
+	return newBufferedSink(os.Stdout, nil, flushInterval, encodeTimingJSON)
+}
+
+func openSinkFile(path string) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sink file %s: %w", path, err)
+	}
+	return file, nil
+}
+
+func encodeTimingJSON(w *bufio.Writer, t TimingInfo) error {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return fmt.Errorf("failed to encode timing: %w", err)
+	}
+	w.Write(data)
+	return w.WriteByte('\n')
+}
+
+func newBufferedSink(w io.Writer, file *os.File, flushInterval time.Duration, encode func(*bufio.Writer, TimingInfo) error) *BufferedSink {
+	if flushInterval <= 0 {
+		flushInterval = defaultSinkFlushInterval
+	}
+	s := &BufferedSink{
+		w:      bufio.NewWriter(w),
+		encode: encode,
+		file:   file,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go s.flushEvery(flushInterval)
+	return s
+}
+
+func (s *BufferedSink) flushEvery(interval time.Duration) {
+	defer close(s.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.mu.Lock()
+			if err := s.flushLocked(); err != nil && s.err == nil {
+				s.err = err
+			}
+			s.mu.Unlock()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// Record buffers one timing record
+func (s *BufferedSink) Record(t TimingInfo) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.takeErr(); err != nil {
+		return err
+	}
+	return s.encode(s.w, t)
+}
+
+// Flush writes out buffered records and syncs the file
+func (s *BufferedSink) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return errors.Join(s.takeErr(), s.flushLocked())
+}
+
+// Close stops the periodic flush, flushes what is buffered and closes the file. Calling it again,
+// e.g. from a deferred Close after an explicit one, returns the first call's result.
+func (s *BufferedSink) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.stop)
+		<-s.done
+
+		s.closeErr = s.Flush()
+		if s.file != nil {
+			if err := s.file.Close(); err != nil {
+				s.closeErr = errors.Join(s.closeErr, fmt.Errorf("failed to close sink file: %w", err))
+			}
+		}
+	})
+	return s.closeErr
+}
+
+func (s *BufferedSink) flushLocked() error {
+	if s.w.Buffered() == 0 {
+		return nil
+	}
+	if err := s.w.Flush(); err != nil {
+		return fmt.Errorf("failed to flush timing sink: %w", err)
+	}
+	if s.file != nil {
+		if err := s.file.Sync(); err != nil {
+			return fmt.Errorf("failed to sync timing sink: %w", err)
+		}
+	}
+	return nil
+}
+
+// takeErr returns and clears a background flush error
+func (s *BufferedSink) takeErr() error {
+	err := s.err
+	s.err = nil
+	return err
+}
+
+// TailHistoryToSink runs TailHistory and records each emitted query in sink as it arrives,
+// flushing sink before returning. Lookup errors are logged and the tail keeps going. It returns
+// when ctx is done, or with the first error from sink.
+func (c *DatabricksRESTClient) TailHistoryToSink(ctx context.Context, since time.Time, interval time.Duration, sink TimingSink) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	rowsCh, errCh := c.TailHistory(ctx, since, interval)
+
+	var sinkErr error
+	for rowsCh != nil || errCh != nil {
+		select {
+		case h, ok := <-rowsCh:
+			if !ok {
+				rowsCh = nil
+				continue
+			}
+			if sinkErr == nil {
+				if sinkErr = sink.Record(h.TimingInfo()); sinkErr != nil {
+					cancel()
+				}
+			}
+		case err, ok := <-errCh:
+			if !ok {
+				errCh = nil
+				continue
+			}
+			c.logger.WarnContext(ctx, "tail history lookup failed", slog.String("error", err.Error()))
+		}
+	}
+
+	if err := sink.Flush(); err != nil {
+		return errors.Join(sinkErr, err)
+	}
+	if sinkErr != nil {
+		return fmt.Errorf("failed to record query history: %w", sinkErr)
+	}
+	return ctx.Err()
+}
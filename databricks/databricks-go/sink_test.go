@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestJSONLSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "timings.jsonl")
+	sink, err := NewJSONLSink(path, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	for _, id := range []string{"q1", "q2"} {
+		if err := sink.Record(TimingInfo{Method: MethodRESTAPI, QueryID: id, DurationMs: 42}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := sink.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	var ids []string
+	for scanner := bufio.NewScanner(file); scanner.Scan(); {
+		var timing TimingInfo
+		if err := json.Unmarshal(scanner.Bytes(), &timing); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, timing.QueryID)
+	}
+	if len(ids) != 2 || ids[0] != "q1" || ids[1] != "q2" {
+		t.Errorf("flushed query IDs = %v, want [q1 q2]", ids)
+	}
+}
+
+func TestCSVSinkAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "timings.csv")
+	for _, id := range []string{"q1", "q2"} {
+		sink, err := NewCSVSink(path, time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := sink.Record(TimingInfo{Method: MethodRESTAPI, QueryID: id}); err != nil {
+			t.Fatal(err)
+		}
+		if err := sink.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The header is written once, when the file is new
+	if len(records) != 3 || records[0][1] != "query_id" || records[1][1] != "q1" || records[2][1] != "q2" {
+		t.Errorf("records = %v, want a header then q1 and q2", records)
+	}
+}
+
+func TestBufferedSinkCloseTwice(t *testing.T) {
+	sink, err := NewJSONLSink(filepath.Join(t.TempDir(), "timings.jsonl"), time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Record(TimingInfo{QueryID: "q1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	first := sink.Close()
+	if first != nil {
+		t.Fatal(first)
+	}
+	if second := sink.Close(); second != first {
+		t.Errorf("second Close = %v, want the first result %v", second, first)
+	}
+}
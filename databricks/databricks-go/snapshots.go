@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Snapshot is one version of a table as reported by DESCRIBE HISTORY
+type Snapshot struct {
+	Version             int64
+	Timestamp           time.Time
+	Operation           string
+	OperationParameters map[string]string
+}
+
+// ListSnapshots runs DESCRIBE HISTORY on a catalog.schema.table and returns its versions,
+// most recent first
+func (c *DatabricksRESTClient) ListSnapshots(ctx context.Context, fullTableName string) ([]Snapshot, error) {
+	if err := validateTableName(fullTableName); err != nil {
+		return nil, err
+	}
+
+	resp, _, err := c.ExecuteStatement(ctx, "DESCRIBE HISTORY "+fullTableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe history of %s: %w", fullTableName, err)
+	}
+	rows, err := decodeRows(resp.Manifest.Schema, resp.Result.DataArray, c.typeConverter())
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode history of %s: %w", fullTableName, err)
+	}
+	return parseSnapshots(resp.Manifest.Schema, rows)
+}
+
+// parseSnapshots maps decoded DESCRIBE HISTORY rows onto Snapshot by column name
+func parseSnapshots(schema Schema, rows [][]any) ([]Snapshot, error) {
+	index := columnIndexes(schema)
+	snapshots := make([]Snapshot, 0, len(rows))
+	for _, row := range rows {
+		r := namedRow{index: index, row: row}
+		s := Snapshot{Operation: r.String("operation")}
+
+		var err error
+		if s.Version, err = r.Int64("version"); err != nil {
+			return nil, err
+		}
+		if s.Timestamp, err = r.Time("timestamp"); err != nil {
+			return nil, err
+		}
+		if s.OperationParameters, err = parseStringMap(r.String("operationParameters")); err != nil {
+			return nil, fmt.Errorf("version %d: column operationParameters: %w", s.Version, err)
+		}
+		snapshots = append(snapshots, s)
+	}
+	return snapshots, nil
+}
+
+// parseStringMap decodes a MAP<STRING, STRING> cell, which JSON_ARRAY renders as a JSON object.
+// Non-string values are kept in their JSON form.
+func parseStringMap(s string) (map[string]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(s), &raw); err != nil {
+		return nil, fmt.Errorf("invalid map value %q: %w", s, err)
+	}
+	m := make(map[string]string, len(raw))
+	for k, v := range raw {
+		var str string
+		if err := json.Unmarshal(v, &str); err == nil {
+			m[k] = str
+		} else {
+			m[k] = string(v)
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"math"
+	"slices"
+	"time"
+)
+
+// TimingStats summarizes the latency of repeated statement executions
+type TimingStats struct {
+	P50, P90, P99, Min, Max, Mean time.Duration
+	// N is the number of successful runs the statistics are computed over
+	N int
+	// Failures counts runs with an ErrorMessage, which are excluded from the statistics
+	Failures int
+	// AtCapacity counts runs that timed out still queued for a busy warehouse; they are excluded
+	// from the statistics and, unlike Failures, point at warehouse sizing rather than the query
+	AtCapacity int
+	// RelativeError bounds the percentiles' error as a fraction of their value when they come
+	// from a streaming estimator; it is 0 when they were computed exactly from every sample.
+	// Min, Max and Mean are always exact.
+	RelativeError float64
+}
+
+// Summarize computes latency statistics over the DurationMs of successful runs.
+// Percentiles use the nearest-rank method: the p-th percentile is the smallest sample
+// such that at least p% of samples are less than or equal to it, i.e. sorted[ceil(p/100*N)-1].
+func Summarize(infos []TimingInfo) TimingStats {
+	acc := timingAccumulator{}
+	for _, info := range infos {
+		acc.add(info)
+	}
+	return acc.stats()
+}
+
+// timingAccumulator collects runs for TimingStats, keeping exact samples until there are more
+// than streamAfter successful runs and folding them into a latencySketch from then on. A zero
+// streamAfter keeps every sample.
+type timingAccumulator struct {
+	streamAfter   int
+	relativeError float64
+
+	durations  []time.Duration
+	sketch     *latencySketch
+	failures   int
+	atCapacity int
+}
+
+func (a *timingAccumulator) add(info TimingInfo) {
+	if info.ErrorMessage != "" {
+		if isQueuedState(info.State) {
+			a.atCapacity++
+		} else {
+			a.failures++
+		}
+		return
+	}
+
+	d := time.Duration(info.DurationMs) * time.Millisecond
+	if a.sketch != nil {
+		a.sketch.add(d)
+		return
+	}
+	a.durations = append(a.durations, d)
+	if a.streamAfter > 0 && len(a.durations) > a.streamAfter {
+		a.sketch = newLatencySketch(a.relativeError)
+		for _, d := range a.durations {
+			a.sketch.add(d)
+		}
+		a.durations = nil
+	}
+}
+
+// count returns how many runs were added, successful or not
+func (a *timingAccumulator) count() int {
+	n := len(a.durations) + a.failures + a.atCapacity
+	if a.sketch != nil {
+		n += int(a.sketch.n)
+	}
+	return n
+}
+
+func (a *timingAccumulator) stats() TimingStats {
+	stats := TimingStats{Failures: a.failures, AtCapacity: a.atCapacity}
+	if a.sketch != nil {
+		sk := a.sketch
+		stats.N = int(sk.n)
+		stats.Min = sk.min
+		stats.Max = sk.max
+		stats.Mean = sk.sum / time.Duration(sk.n)
+		stats.P50 = sk.quantile(50)
+		stats.P90 = sk.quantile(90)
+		stats.P99 = sk.quantile(99)
+		stats.RelativeError = sk.alpha
+		return stats
+	}
+
+	durations := slices.Clone(a.durations)
+	stats.N = len(durations)
+	if stats.N == 0 {
+		return stats
+	}
+
+	slices.Sort(durations)
+	var total time.Duration
+	for _, d := range durations {
+		total += d
+	}
+
+	stats.Min = durations[0]
+	stats.Max = durations[stats.N-1]
+	stats.Mean = total / time.Duration(stats.N)
+	stats.P50 = nearestRank(durations, 50)
+	stats.P90 = nearestRank(durations, 90)
+	stats.P99 = nearestRank(durations, 99)
+	return stats
+}
+
+// SummarizeColdWarm partitions runs by their history entry's WasColdStart and summarizes each
+// bucket separately, so warehouse start-up latency does not skew warm execution statistics.
+// Runs are matched to history by QueryID; runs without a history entry are left out of both.
+func SummarizeColdWarm(infos []TimingInfo, history []QueryHistoryResponse) (cold, warm TimingStats) {
+	coldStarts := make(map[string]bool, len(history))
+	for _, h := range history {
+		coldStarts[h.StatementID] = h.WasColdStart()
+	}
+
+	var coldInfos, warmInfos []TimingInfo
+	for _, info := range infos {
+		isCold, ok := coldStarts[info.QueryID]
+		switch {
+		case !ok:
+		case isCold:
+			coldInfos = append(coldInfos, info)
+		default:
+			warmInfos = append(warmInfos, info)
+		}
+	}
+	return Summarize(coldInfos), Summarize(warmInfos)
+}
+
+// SummarizeUncached summarizes the runs whose history entry does not have FromResultCache set,
+// since a cached result has near-zero latency that says nothing about execution. Runs are matched
+// to history by QueryID; runs without a history entry are kept, as they can't be shown to be cached.
+func SummarizeUncached(infos []TimingInfo, history []QueryHistoryResponse) TimingStats {
+	cached := make(map[string]bool, len(history))
+	for _, h := range history {
+		cached[h.StatementID] = h.FromResultCache
+	}
+
+	uncached := make([]TimingInfo, 0, len(infos))
+	for _, info := range infos {
+		if !cached[info.QueryID] {
+			uncached = append(uncached, info)
+		}
+	}
+	return Summarize(uncached)
+}
+
+// nearestRank returns the p-th percentile of an ascending, non-empty slice
+func nearestRank(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	rank = max(rank, 1)
+	return sorted[rank-1]
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+// StreamRows runs a statement and calls fn for each decoded row, fetching chunks (inline or via
+// external links) lazily so only one chunk is held in memory at a time. If fn returns an error,
+// streaming stops and that error is returned unwrapped.
+func (c *DatabricksRESTClient) StreamRows(ctx context.Context, statement string, fn func(row []any) error) (*TimingInfo, error) {
+	return c.streamRows(ctx, statement, func(_ Schema, row []any) error {
+		return fn(row)
+	})
+}
+
+// streamRows is StreamRows with the result schema passed to fn alongside each row
+func (c *DatabricksRESTClient) streamRows(ctx context.Context, statement string, fn func(schema Schema, row []any) error) (*TimingInfo, error) {
+	timing, resp, err := c.executeStatement(ctx, c.newStatementRequest(statement))
+	if err != nil {
+		return timing, err
+	}
+
+	ctx, transfer := withTransferStats(ctx)
+	defer transfer.applyTo(timing)
+
+	err = c.walkChunks(ctx, resp.StatementID, resp.Manifest, &resp.Result, func(chunk *ResultData) error {
+		rows, err := c.decodeChunk(ctx, resp.Manifest.Schema, chunk)
+		if err != nil {
+			return newChunkFetchError(resp.StatementID, chunk.index(), fmt.Errorf("failed to decode: %w", err))
+		}
+		for _, row := range rows {
+			if err := fn(resp.Manifest.Schema, row); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return timing, err
+	}
+
+	if c.CloseAfterFetch {
+		if err := c.CloseStatement(ctx, resp.StatementID); err != nil {
+			return timing, err
+		}
+	}
+	return timing, nil
+}
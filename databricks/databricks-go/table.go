@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// identifierPart matches a plain SQL identifier or a backquoted one without embedded backquotes
+var identifierPart = regexp.MustCompile("^(?:[A-Za-z_][A-Za-z0-9_]*|`[^`]+`)$")
+
+// validateTableName checks that name is a three-part catalog.schema.table identifier so it can be
+// interpolated into maintenance statements without opening an injection path
+func validateTableName(name string) error {
+	parts := splitTableName(name)
+	if len(parts) != 3 {
+		return fmt.Errorf("invalid table name %q: expected catalog.schema.table", name)
+	}
+	for _, part := range parts {
+		if !identifierPart.MatchString(part) {
+			return fmt.Errorf("invalid table name %q: bad identifier %q", name, part)
+		}
+	}
+	return nil
+}
+
+// splitTableName splits on dots outside backquotes
+func splitTableName(name string) []string {
+	var (
+		parts  []string
+		quoted bool
+		start  int
+	)
+	for i, r := range name {
+		switch {
+		case r == '`':
+			quoted = !quoted
+		case r == '.' && !quoted:
+			parts = append(parts, name[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, name[start:])
+}
+
+// quoteIdentifier backquotes a single column identifier, escaping embedded backquotes
+func quoteIdentifier(name string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("empty identifier")
+	}
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`", nil
+}
+
+// quoteString renders s as a single-quoted SQL string literal. Databricks SQL treats backslash as
+// an escape character inside literals, so backslashes are escaped along with quotes.
+func quoteString(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
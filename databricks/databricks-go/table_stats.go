@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// TableStats is the size and layout of a table as reported by DESCRIBE DETAIL
+type TableStats struct {
+	Name             string
+	Format           string
+	NumFiles         int64
+	SizeInBytes      int64
+	PartitionColumns []string
+	MinReaderVersion int64
+	CreatedAt        time.Time
+	LastModified     time.Time
+}
+
+// TableStats runs DESCRIBE DETAIL on a catalog.schema.table and returns its file count, size,
+// partitioning and timestamps
+func (c *DatabricksRESTClient) TableStats(ctx context.Context, fullTableName string) (TableStats, error) {
+	if err := validateTableName(fullTableName); err != nil {
+		return TableStats{}, err
+	}
+
+	resp, _, err := c.ExecuteStatement(ctx, "DESCRIBE DETAIL "+fullTableName)
+	if err != nil {
+		return TableStats{}, fmt.Errorf("failed to describe detail of %s: %w", fullTableName, err)
+	}
+	rows, err := decodeRows(resp.Manifest.Schema, resp.Result.DataArray, c.typeConverter())
+	if err != nil {
+		return TableStats{}, fmt.Errorf("failed to decode detail of %s: %w", fullTableName, err)
+	}
+	if len(rows) == 0 {
+		return TableStats{}, fmt.Errorf("describe detail of %s returned no rows", fullTableName)
+	}
+
+	stats, err := parseTableStats(resp.Manifest.Schema, rows[0])
+	if err != nil {
+		return TableStats{}, fmt.Errorf("failed to parse detail of %s: %w", fullTableName, err)
+	}
+	return stats, nil
+}
+
+// parseTableStats maps a decoded DESCRIBE DETAIL row onto TableStats by column name
+func parseTableStats(schema Schema, row []any) (TableStats, error) {
+	r := namedRow{index: columnIndexes(schema), row: row}
+	s := TableStats{
+		Name:   r.String("name"),
+		Format: r.String("format"),
+	}
+
+	var err error
+	if s.NumFiles, err = r.Int64("numFiles"); err != nil {
+		return TableStats{}, err
+	}
+	if s.SizeInBytes, err = r.Int64("sizeInBytes"); err != nil {
+		return TableStats{}, err
+	}
+	if s.MinReaderVersion, err = r.Int64("minReaderVersion"); err != nil {
+		return TableStats{}, err
+	}
+	if s.CreatedAt, err = r.Time("createdAt"); err != nil {
+		return TableStats{}, err
+	}
+	if s.LastModified, err = r.Time("lastModified"); err != nil {
+		return TableStats{}, err
+	}
+	// ARRAY<STRING> cells are rendered as a JSON array
+	if cols := r.String("partitionColumns"); cols != "" {
+		if err := json.Unmarshal([]byte(cols), &s.PartitionColumns); err != nil {
+			return TableStats{}, fmt.Errorf("column partitionColumns: invalid array %q: %w", cols, err)
+		}
+	}
+	return s, nil
+}
+
+// AverageFileSize returns the mean data file size in bytes, or 0 for a table without files
+func (s TableStats) AverageFileSize() int64 {
+	if s.NumFiles <= 0 {
+		return 0
+	}
+	return s.SizeInBytes / s.NumFiles
+}
+
+// SnapshotAge returns how long ago the table was last modified, or 0 when that is unknown
+func (s TableStats) SnapshotAge() time.Duration {
+	if s.LastModified.IsZero() {
+		return 0
+	}
+	return time.Since(s.LastModified)
+}
+
+// NeedsOptimize reports whether the table has too many small files, judged against threshold:
+// the table must have at least threshold.NumFiles files and an average file size below
+// threshold.SizeInBytes. A zero field in threshold disables that condition, so a zero threshold
+// never recommends OPTIMIZE.
+func (s TableStats) NeedsOptimize(threshold TableStats) bool {
+	if threshold.NumFiles <= 0 && threshold.SizeInBytes <= 0 {
+		return false
+	}
+	if threshold.NumFiles > 0 && s.NumFiles < threshold.NumFiles {
+		return false
+	}
+	if threshold.SizeInBytes > 0 && s.AverageFileSize() >= threshold.SizeInBytes {
+		return false
+	}
+	return s.NumFiles > 1
+}
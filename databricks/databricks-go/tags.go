@@ -0,0 +1,35 @@
+package main
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// tagCommentPattern finds the query_tags comment written by tagComment. The encoded JSON never
+// contains "*/", so the first " */" ends the comment.
+var tagCommentPattern = regexp.MustCompile(`/\* query_tags=(.*?) \*/`)
+
+// tagComment renders tags as a leading /* query_tags={...} */ comment, or "" when there are none.
+// Keys are sorted, and any "*/" inside the JSON is escaped so tag values cannot end the comment.
+func tagComment(tags map[string]string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	// Marshaling a map of strings cannot fail
+	encoded, _ := json.Marshal(tags)
+	return "/* query_tags=" + strings.ReplaceAll(string(encoded), "*/", `*\/`) + " */ "
+}
+
+// Tags returns the tags that ExecOptions.Tags attached to the statement, or nil if it has none
+func (q QueryHistoryResponse) Tags() map[string]string {
+	match := tagCommentPattern.FindStringSubmatch(q.StatementText)
+	if match == nil {
+		return nil
+	}
+	var tags map[string]string
+	if err := json.Unmarshal([]byte(match[1]), &tags); err != nil {
+		return nil
+	}
+	return tags
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// TailHistory polls system.query.history every interval and emits each query that finished after
+// since, oldest first. Rows are deduplicated by statement ID across polls. Lookup errors are sent
+// on the error channel and the tail keeps going; both channels are closed once ctx is done.
+// Callers should drain both channels, since sends block until received or ctx is done.
+func (c *DatabricksRESTClient) TailHistory(ctx context.Context, since time.Time, interval time.Duration) (<-chan QueryHistoryResponse, <-chan error) {
+	rowsCh := make(chan QueryHistoryResponse)
+	errCh := make(chan error)
+
+	go func() {
+		defer close(rowsCh)
+		defer close(errCh)
+
+		watermark := since
+		// Statements whose end_time equals the watermark were already emitted. The next poll
+		// uses >= so rows sharing that timestamp are not missed, and this set filters repeats.
+		seenAtWatermark := map[string]bool{}
+
+		for {
+			query := fmt.Sprintf("SELECT %s FROM system.query.history WHERE end_time >= %s ORDER BY end_time, statement_id",
+				queryHistoryColumns, sqlTimestamp(watermark))
+			history, err := c.queryHistory(ctx, query)
+			if err != nil && ctx.Err() == nil {
+				select {
+				case errCh <- fmt.Errorf("tail history: %w", err):
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			for _, h := range history {
+				if h.EndTime.Equal(watermark) && seenAtWatermark[h.StatementID] {
+					continue
+				}
+				select {
+				case rowsCh <- h:
+				case <-ctx.Done():
+					return
+				}
+
+				if h.EndTime.After(watermark) {
+					watermark = h.EndTime
+					seenAtWatermark = map[string]bool{}
+				}
+				seenAtWatermark[h.StatementID] = true
+			}
+
+			if err := sleepContext(ctx, interval); err != nil {
+				return
+			}
+		}
+	}()
+
+	return rowsCh, errCh
+}
+
+// sqlTimestamp renders t as a Spark SQL TIMESTAMP literal in UTC
+func sqlTimestamp(t time.Time) string {
+	return "TIMESTAMP '" + t.UTC().Format("2006-01-02 15:04:05.000000Z") + "'"
+}
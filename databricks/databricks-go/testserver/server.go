@@ -0,0 +1,400 @@
+// Package testserver provides an in-process mock of the Databricks SQL Statement Execution,
+// sessions and query history APIs, so the REST client's timing flows can be exercised without a
+// workspace.
+//
+// A client is pointed at the mock by using the server's host as hostname and its TLS client:
+//
+//	srv := testserver.NewMockServer()
+//	defer srv.Close()
+//	testserver.HandlerFor(srv).AddStatement(testserver.Statement{Text: "SELECT 1", Rows: testserver.IntRows(1)})
+//	client := NewDatabricksRESTClient(srv.Listener.Addr().String(), "token", "warehouse", WithHTTPClient(srv.Client()))
+package testserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Statement is a canned statement result served for requests whose statement text matches Text
+type Statement struct {
+	// ID is the statement_id returned to the client; one is generated when empty
+	ID string
+	// Text is the exact statement text that selects this response
+	Text string
+	// Columns describes the result schema; a single LONG column named "n" is used when empty
+	Columns []Column
+	// Rows is the JSON_ARRAY result, split into chunks of ChunkSize rows
+	Rows [][]*string
+	// ChunkSize is the number of rows per chunk; zero serves every row in one chunk
+	ChunkSize int
+	// RunningPolls is how many responses report RUNNING before the statement succeeds,
+	// scripting a RUNNING→SUCCEEDED transition across the POST and subsequent GETs
+	RunningPolls int
+	// Error, when set, makes the statement finish in the FAILED state with this error
+	Error *StatementError
+}
+
+// Column is a result column in a Statement's manifest
+type Column struct {
+	Name     string
+	TypeName string
+	// TypeText is the full type such as "DECIMAL(38,18)"; TypeName is used when empty
+	TypeText string
+}
+
+// StatementError is the error reported for a FAILED statement
+type StatementError struct {
+	ErrorCode string
+	Message   string
+}
+
+// IntRows returns n single-column rows holding "0" through "n-1", for tests that only care
+// about row counts
+func IntRows(n int) [][]*string {
+	rows := make([][]*string, n)
+	for i := range rows {
+		s := strconv.Itoa(i)
+		rows[i] = []*string{&s}
+	}
+	return rows
+}
+
+// Execution is a statement submission the mock received
+type Execution struct {
+	Text string
+	// SessionID is the session the statement ran in, or empty
+	SessionID string
+	// Parameters holds the bound named parameters by name
+	Parameters map[string]string
+}
+
+// Handler serves the mock API. It is safe for concurrent use.
+type Handler struct {
+	mu         sync.Mutex
+	nextID     int
+	byText     map[string]*statementState
+	byID       map[string]*statementState
+	history    map[string]*historyState
+	sessions   map[string]bool
+	executions []Execution
+	failures   []int
+	requestLog []string
+}
+
+type statementState struct {
+	Statement
+	state        string
+	pollsPending int
+}
+
+type historyState struct {
+	body         any
+	missingPolls int
+}
+
+// NewHandler returns an empty mock API handler
+func NewHandler() *Handler {
+	return &Handler{
+		byText:   make(map[string]*statementState),
+		byID:     make(map[string]*statementState),
+		history:  make(map[string]*historyState),
+		sessions: make(map[string]bool),
+	}
+}
+
+// NewMockServer starts a TLS server backed by a new Handler. Use HandlerFor to register responses.
+func NewMockServer() *httptest.Server {
+	return httptest.NewTLSServer(NewHandler())
+}
+
+// HandlerFor returns the Handler behind a server created by NewMockServer
+func HandlerFor(srv *httptest.Server) *Handler {
+	h, ok := srv.Config.Handler.(*Handler)
+	if !ok {
+		panic("testserver: server was not created by NewMockServer")
+	}
+	return h
+}
+
+// AddStatement registers a canned statement and returns its statement ID
+func (h *Handler) AddStatement(s Statement) string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if s.ID == "" {
+		h.nextID++
+		s.ID = fmt.Sprintf("00000000-0000-0000-0000-%012d", h.nextID)
+	}
+	if len(s.Columns) == 0 {
+		s.Columns = []Column{{Name: "n", TypeName: "LONG"}}
+	}
+	st := &statementState{Statement: s, state: "PENDING"}
+	h.byText[s.Text] = st
+	h.byID[s.ID] = st
+	return s.ID
+}
+
+// AddHistoryQuery registers the body served by /api/2.0/sql/history/queries/{id}. The first
+// missingPolls lookups answer 404 RESOURCE_DOES_NOT_EXIST, as if the entry had not materialized.
+func (h *Handler) AddHistoryQuery(statementID string, body any, missingPolls int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.history[statementID] = &historyState{body: body, missingPolls: missingPolls}
+}
+
+// FailRequests makes the next len(statuses) requests fail with the given HTTP statuses, in order,
+// e.g. to exercise retries on 429 or 503
+func (h *Handler) FailRequests(statuses ...int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.failures = append(h.failures, statuses...)
+}
+
+// Executions returns every statement submitted so far, in order
+func (h *Handler) Executions() []Execution {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]Execution(nil), h.executions...)
+}
+
+// OpenSessions returns the IDs of the sessions opened and not yet closed
+func (h *Handler) OpenSessions() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var ids []string
+	for id, open := range h.sessions {
+		if open {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// Requests returns the "METHOD path" of every request served so far
+func (h *Handler) Requests() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.requestLog...)
+}
+
+// ServeHTTP implements http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.requestLog = append(h.requestLog, r.Method+" "+r.URL.Path)
+	if len(h.failures) > 0 {
+		status := h.failures[0]
+		h.failures = h.failures[1:]
+		writeError(w, status, "TEMPORARILY_UNAVAILABLE", "injected failure")
+		return
+	}
+
+	const statementsPath = "/api/2.0/sql/statements"
+	const historyPath = "/api/2.0/sql/history/queries/"
+	const sessionsPath = "/api/2.0/sql/sessions"
+	path := r.URL.Path
+	switch {
+	case r.Method == http.MethodPost && path == "/oidc/v1/token":
+		writeJSON(w, map[string]any{"access_token": "mock-token", "token_type": "Bearer", "expires_in": 3600})
+	case r.Method == http.MethodPost && (path == statementsPath || path == statementsPath+"/"):
+		h.executeStatement(w, r)
+	case strings.HasPrefix(path, statementsPath+"/"):
+		h.statementRequest(w, r, strings.Split(strings.TrimPrefix(path, statementsPath+"/"), "/"))
+	case r.Method == http.MethodPost && path == sessionsPath:
+		h.nextID++
+		id := fmt.Sprintf("session-%d", h.nextID)
+		h.sessions[id] = true
+		writeJSON(w, map[string]string{"session_id": id})
+	case r.Method == http.MethodDelete && strings.HasPrefix(path, sessionsPath+"/"):
+		id := strings.TrimPrefix(path, sessionsPath+"/")
+		if !h.sessions[id] {
+			writeError(w, http.StatusNotFound, "RESOURCE_DOES_NOT_EXIST", "session "+id+" not found")
+			return
+		}
+		h.sessions[id] = false
+		writeJSON(w, struct{}{})
+	case r.Method == http.MethodGet && strings.HasPrefix(path, historyPath):
+		h.historyQuery(w, strings.TrimPrefix(path, historyPath))
+	default:
+		writeError(w, http.StatusNotFound, "ENDPOINT_NOT_FOUND", "no mock for "+r.Method+" "+path)
+	}
+}
+
+func (h *Handler) executeStatement(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Statement  string `json:"statement"`
+		SessionID  string `json:"session_id"`
+		Parameters []struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"parameters"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_PARAMETER_VALUE", "malformed request body")
+		return
+	}
+	if req.SessionID != "" && !h.sessions[req.SessionID] {
+		writeError(w, http.StatusNotFound, "RESOURCE_DOES_NOT_EXIST", "session "+req.SessionID+" not found")
+		return
+	}
+	var params map[string]string
+	for _, p := range req.Parameters {
+		if params == nil {
+			params = make(map[string]string)
+		}
+		params[p.Name] = p.Value
+	}
+	h.executions = append(h.executions, Execution{Text: req.Statement, SessionID: req.SessionID, Parameters: params})
+	st, ok := h.byText[req.Statement]
+	if !ok {
+		writeError(w, http.StatusBadRequest, "INVALID_PARAMETER_VALUE", fmt.Sprintf("no mock statement registered for %q", req.Statement))
+		return
+	}
+
+	st.state = "RUNNING"
+	st.pollsPending = st.RunningPolls
+	h.advance(st)
+	writeJSON(w, st.response())
+}
+
+// statementRequest handles GET/DELETE /{id}, POST /{id}/cancel and GET /{id}/result/chunks/{n}
+func (h *Handler) statementRequest(w http.ResponseWriter, r *http.Request, parts []string) {
+	st, ok := h.byID[parts[0]]
+	if !ok || st.state == "CLOSED" {
+		writeError(w, http.StatusNotFound, "RESOURCE_DOES_NOT_EXIST", "statement "+parts[0]+" not found")
+		return
+	}
+
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		h.advance(st)
+		writeJSON(w, st.response())
+	case len(parts) == 1 && r.Method == http.MethodDelete:
+		st.state = "CLOSED"
+		writeJSON(w, struct{}{})
+	case len(parts) == 2 && parts[1] == "cancel" && r.Method == http.MethodPost:
+		if st.state == "PENDING" || st.state == "RUNNING" {
+			st.state = "CANCELED"
+		}
+		writeJSON(w, struct{}{})
+	case len(parts) == 4 && parts[1] == "result" && parts[2] == "chunks" && r.Method == http.MethodGet:
+		index, err := strconv.Atoi(parts[3])
+		if err != nil || st.state != "SUCCEEDED" || index < 0 || index >= st.chunkCount() {
+			writeError(w, http.StatusBadRequest, "INVALID_PARAMETER_VALUE", "invalid chunk "+parts[3])
+			return
+		}
+		writeJSON(w, st.chunk(index))
+	default:
+		writeError(w, http.StatusNotFound, "ENDPOINT_NOT_FOUND", "no mock for "+r.Method+" "+r.URL.Path)
+	}
+}
+
+func (h *Handler) historyQuery(w http.ResponseWriter, statementID string) {
+	hist, ok := h.history[statementID]
+	if !ok || hist.missingPolls > 0 {
+		if ok {
+			hist.missingPolls--
+		}
+		writeError(w, http.StatusNotFound, "RESOURCE_DOES_NOT_EXIST", "query "+statementID+" not found")
+		return
+	}
+	writeJSON(w, hist.body)
+}
+
+// advance moves a running statement one poll closer to its final state
+func (h *Handler) advance(st *statementState) {
+	if st.state != "RUNNING" {
+		return
+	}
+	if st.pollsPending > 0 {
+		st.pollsPending--
+		return
+	}
+	if st.Error != nil {
+		st.state = "FAILED"
+	} else {
+		st.state = "SUCCEEDED"
+	}
+}
+
+func (st *statementState) chunkSize() int {
+	if st.ChunkSize <= 0 {
+		return max(len(st.Rows), 1)
+	}
+	return st.ChunkSize
+}
+
+func (st *statementState) chunkCount() int {
+	if len(st.Rows) == 0 {
+		return 0
+	}
+	return (len(st.Rows) + st.chunkSize() - 1) / st.chunkSize()
+}
+
+func (st *statementState) response() map[string]any {
+	status := map[string]any{"state": st.state}
+	if st.state == "FAILED" {
+		status["error"] = map[string]any{"error_code": st.Error.ErrorCode, "message": st.Error.Message}
+	}
+	resp := map[string]any{
+		"statement_id": st.ID,
+		"status":       status,
+	}
+	if st.state != "SUCCEEDED" {
+		return resp
+	}
+
+	columns := make([]map[string]any, len(st.Columns))
+	for i, col := range st.Columns {
+		typeText := col.TypeText
+		if typeText == "" {
+			typeText = col.TypeName
+		}
+		columns[i] = map[string]any{"name": col.Name, "type_name": col.TypeName, "type_text": typeText, "position": i}
+	}
+	resp["manifest"] = map[string]any{
+		"format":            "JSON_ARRAY",
+		"schema":            map[string]any{"column_count": len(st.Columns), "columns": columns},
+		"total_chunk_count": st.chunkCount(),
+		"total_row_count":   len(st.Rows),
+	}
+	if st.chunkCount() > 0 {
+		resp["result"] = st.chunk(0)
+	} else {
+		resp["result"] = map[string]any{}
+	}
+	return resp
+}
+
+func (st *statementState) chunk(index int) map[string]any {
+	start := index * st.chunkSize()
+	end := min(start+st.chunkSize(), len(st.Rows))
+	chunk := map[string]any{
+		"chunk_index": index,
+		"row_offset":  start,
+		"row_count":   end - start,
+		"data_array":  st.Rows[start:end],
+	}
+	if index+1 < st.chunkCount() {
+		chunk["next_chunk_index"] = index + 1
+		chunk["next_chunk_internal_link"] = fmt.Sprintf("/api/2.0/sql/statements/%s/result/chunks/%d", st.ID, index+1)
+	}
+	return chunk
+}
+
+func writeJSON(w http.ResponseWriter, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error_code": code, "message": message})
+}
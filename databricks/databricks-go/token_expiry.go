@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrTokenExpiresSoon is returned by CheckTokenLifetime when the credential would expire before
+// the work it is checked for could finish
+var ErrTokenExpiresSoon = errors.New("token expires before the run would finish")
+
+// tokenListResponse is the response from /api/2.0/token/list
+type tokenListResponse struct {
+	TokenInfos []struct {
+		TokenID string `json:"token_id"`
+		// ExpiryTime is in epoch milliseconds, or -1 for a token that never expires
+		ExpiryTime int64 `json:"expiry_time"`
+	} `json:"token_infos"`
+}
+
+// TokenExpiresAt returns when the client's credential expires; the zero time means it does not.
+// For OAuth clients the access token is refreshed first if it is close to expiry. A JWT bearer
+// token is read from its exp claim. For a PAT the token list API is consulted, which can't tell
+// which of the user's tokens is in use, so the earliest expiry among them is returned.
+func (c *DatabricksRESTClient) TokenExpiresAt(ctx context.Context) (time.Time, error) {
+	if p, ok := c.credentials.(*OAuthProvider); ok {
+		if _, err := p.Token(ctx); err != nil {
+			return time.Time{}, err
+		}
+		return p.expiry(), nil
+	}
+
+	token, err := c.credentials.Token(ctx)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get credentials: %w", err)
+	}
+	if exp, ok := jwtExpiry(token); ok {
+		return exp, nil
+	}
+
+	var resp tokenListResponse
+	if err := c.doJSON(ctx, "GET", "/api/2.0/token/list", nil, &resp); err != nil {
+		return time.Time{}, fmt.Errorf("failed to list tokens: %w", err)
+	}
+	var earliest time.Time
+	for _, info := range resp.TokenInfos {
+		if info.ExpiryTime <= 0 {
+			continue
+		}
+		expiry := epochMsToTime(info.ExpiryTime)
+		if expiry.After(time.Now()) && (earliest.IsZero() || expiry.Before(earliest)) {
+			earliest = expiry
+		}
+	}
+	return earliest, nil
+}
+
+// CheckTokenLifetime fails fast with ErrTokenExpiresSoon when the credential expires within d,
+// e.g. the estimated duration of a nightly batch, instead of letting the batch die halfway with
+// a 401. OAuth clients refresh their access token, which is renewed automatically during the
+// run, so only a failed refresh is reported for them.
+func (c *DatabricksRESTClient) CheckTokenLifetime(ctx context.Context, d time.Duration) error {
+	if p, ok := c.credentials.(*OAuthProvider); ok {
+		if err := p.refresh(ctx); err != nil {
+			return fmt.Errorf("failed to refresh OAuth token: %w", err)
+		}
+		return nil
+	}
+
+	expiresAt, err := c.TokenExpiresAt(ctx)
+	if err != nil {
+		return err
+	}
+	if !expiresAt.IsZero() && time.Until(expiresAt) < d {
+		return fmt.Errorf("%w: token expires at %s, run needs %s", ErrTokenExpiresSoon, expiresAt.Format(time.RFC3339), d)
+	}
+	return nil
+}
+
+// jwtExpiry reads the exp claim of a JWT without verifying it; ok is false for non-JWT tokens
+func jwtExpiry(token string) (time.Time, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}, false
+	}
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(claims.Exp, 0), true
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	tracerName           = "databricks-go-timing-test"
+	spanStatementExecute = "databricks.statement.execute"
+	spanStatementGet     = "databricks.statement.get"
+)
+
+// startSpan starts a client span using the globally registered tracer provider, which is a
+// no-op unless the application installs one
+func startSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient))
+}
+
+// endStatementSpan records the statement attributes and any error, then ends the span
+func endStatementSpan(span trace.Span, warehouseID, statementID string, resp *StatementExecutionResponse, err error) {
+	span.SetAttributes(attribute.String("warehouse_id", warehouseID))
+	if resp != nil {
+		if resp.StatementID != "" {
+			statementID = resp.StatementID
+		}
+		span.SetAttributes(
+			attribute.String("state", resp.Status.State),
+			attribute.Int64("row_count", resp.Manifest.TotalRowCount),
+		)
+	}
+	if statementID != "" {
+		span.SetAttributes(attribute.String("statement_id", statementID))
+	}
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// injectTraceContext propagates the span in ctx as a W3C traceparent header
+func injectTraceContext(ctx context.Context, header http.Header) {
+	propagation.TraceContext{}.Inject(ctx, propagation.HeaderCarrier(header))
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// transferStats accumulates result download metrics for one statement fetch. Chunk fetchers
+// record into the stats carried by their context, so the metrics cover exactly the downloads
+// made on behalf of that statement.
+type transferStats struct {
+	mu      sync.Mutex
+	bytes   int64
+	chunks  int
+	elapsed time.Duration
+}
+
+type transferStatsKey struct{}
+
+// withTransferStats returns a context whose chunk downloads are recorded in the returned stats
+func withTransferStats(ctx context.Context) (context.Context, *transferStats) {
+	stats := &transferStats{}
+	return context.WithValue(ctx, transferStatsKey{}, stats), stats
+}
+
+// recordTransfer adds one download to the stats carried by ctx, if any. countChunk is false for
+// requests that only return chunk metadata, such as a chunk whose data sits behind external links.
+func recordTransfer(ctx context.Context, bytes int, countChunk bool, elapsed time.Duration) {
+	stats, ok := ctx.Value(transferStatsKey{}).(*transferStats)
+	if !ok {
+		return
+	}
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+	stats.bytes += int64(bytes)
+	stats.elapsed += elapsed
+	if countChunk {
+		stats.chunks++
+	}
+}
+
+// applyTo copies the accumulated metrics into timing
+func (s *transferStats) applyTo(timing *TimingInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	timing.BytesDownloaded = s.bytes
+	timing.ChunkDownloadMs = s.elapsed.Milliseconds()
+	timing.NumChunksFetched = s.chunks
+}
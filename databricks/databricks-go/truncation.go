@@ -0,0 +1,48 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrResultTruncated is returned alongside the partial rows when a result holds fewer rows than
+// the statement produced, typically because INLINE results are capped in size
+var ErrResultTruncated = errors.New("result truncated")
+
+// IsTruncated reports whether the response holds fewer rows than the statement produced, either
+// because the manifest says so or because DataArray is shorter than TotalRowCount. Call it on a
+// response from ExecuteStatement, whose DataArray holds every chunk.
+func (resp *StatementExecutionResponse) IsTruncated() bool {
+	return resp.Manifest.Truncated || int64(len(resp.Result.DataArray)) < resp.Manifest.TotalRowCount
+}
+
+func truncationError(statementID, disposition string, got int, total int64) error {
+	err := fmt.Errorf("statement %s returned %d of %d rows: %w", statementID, got, total, ErrResultTruncated)
+	if disposition == DispositionInline {
+		return fmt.Errorf("%w; use the %s disposition (ExecuteAndFetchExternalLinks) for large results", err, DispositionExternalLinks)
+	}
+	return err
+}
+
+// returnedRowCount sums the rows of the manifest's chunks, falling back to total_row_count when
+// the manifest does not list them
+func (resp *StatementExecutionResponse) returnedRowCount() int64 {
+	if len(resp.Manifest.Chunks) == 0 {
+		return resp.Manifest.TotalRowCount
+	}
+	var n int64
+	for _, chunk := range resp.Manifest.Chunks {
+		n += chunk.RowCount
+	}
+	return n
+}
+
+// RemainingEstimate returns how many rows a truncated result left out, i.e. total_row_count
+// minus the rows returned, for a "showing 1000 of ~50000" preview. ok is false when the result
+// is complete or the API did not report a total beyond the rows it returned.
+func (t *TimingInfo) RemainingEstimate() (remaining int64, ok bool) {
+	if !t.Truncated || t.RowCount <= t.ReturnedRowCount {
+		return 0, false
+	}
+	return t.RowCount - t.ReturnedRowCount, true
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"runtime"
+	"strings"
+)
+
+// defaultUserAgent identifies this tool, its build version and the Go runtime that built it
+func defaultUserAgent() string {
+	return "databricks-iceberg-ops/" + Version() + " go/" + strings.TrimPrefix(runtime.Version(), "go")
+}
+
+// WithUserAgent appends product, e.g. "nightly-compaction/2.3", to the default User-Agent so the
+// platform team can attribute and rate-limit traffic per application
+func WithUserAgent(product string) ClientOption {
+	return func(c *DatabricksRESTClient) {
+		if product = strings.TrimSpace(product); product != "" {
+			c.userAgent += " " + product
+		}
+	}
+}
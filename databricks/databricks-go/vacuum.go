@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// defaultVacuumRetentionHours is the minimum retention VACUUM accepts without disabling its safety check
+const defaultVacuumRetentionHours = 168
+
+// VacuumTable removes files no longer referenced by a catalog.schema.table and older than
+// retainHours. With dryRun it deletes nothing and returns the paths that would be removed.
+// A retention below 168 hours is rejected unless AllowShortVacuumRetention is set, since it can
+// delete files still needed for time travel.
+func (c *DatabricksRESTClient) VacuumTable(ctx context.Context, fullTableName string, retainHours float64, dryRun bool) ([]string, error) {
+	if err := validateTableName(fullTableName); err != nil {
+		return nil, err
+	}
+	if math.IsNaN(retainHours) || math.IsInf(retainHours, 0) || retainHours < 0 {
+		return nil, fmt.Errorf("invalid retention %v hours", retainHours)
+	}
+	if retainHours < defaultVacuumRetentionHours && !c.AllowShortVacuumRetention {
+		return nil, fmt.Errorf("retention of %v hours is below the %d-hour safety threshold; set AllowShortVacuumRetention to override",
+			retainHours, defaultVacuumRetentionHours)
+	}
+
+	stmt := fmt.Sprintf("VACUUM %s RETAIN %s HOURS", fullTableName, strconv.FormatFloat(retainHours, 'f', -1, 64))
+	if dryRun {
+		stmt += " DRY RUN"
+	}
+
+	resp, _, err := c.ExecuteStatement(ctx, stmt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to vacuum %s: %w", fullTableName, err)
+	}
+	if !dryRun {
+		return nil, nil
+	}
+
+	col, ok := resp.Manifest.Schema.ColumnIndex("path")
+	if !ok {
+		return nil, fmt.Errorf("vacuum dry run of %s returned no path column", fullTableName)
+	}
+	paths := make([]string, 0, len(resp.Result.DataArray))
+	for _, row := range resp.Result.DataArray {
+		if col < len(row) && row[col] != nil {
+			paths = append(paths, *row[col])
+		}
+	}
+	return paths, nil
+}
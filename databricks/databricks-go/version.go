@@ -0,0 +1,29 @@
+package main
+
+// Build metadata, set at link time:
+//
+//	go build -ldflags "-X main.version=1.4.0 -X main.commit=$(git rev-parse --short HEAD) -X main.buildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// A plain go build or go run reports version "dev" with an empty commit and build date.
+var (
+	version   = "dev"
+	commit    = ""
+	buildDate = ""
+)
+
+// BuildInfo identifies the build that produced a timing report
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildDate string `json:"build_date,omitempty"`
+}
+
+// Version returns the version this binary was built as, or "dev" without ldflags
+func Version() string {
+	return version
+}
+
+// CurrentBuild returns the version, commit and build date this binary was built with
+func CurrentBuild() BuildInfo {
+	return BuildInfo{Version: version, Commit: commit, BuildDate: buildDate}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// VolumeEntry is a file or subdirectory listed by ListVolumeDir
+type VolumeEntry struct {
+	Path  string
+	IsDir bool
+	// FileSize and LastModified are zero for directories
+	FileSize     int64
+	LastModified time.Time
+}
+
+// volumeDirPage is one page of GET /api/2.0/fs/directories
+type volumeDirPage struct {
+	Contents []struct {
+		Path         string `json:"path"`
+		IsDirectory  bool   `json:"is_directory"`
+		FileSize     int64  `json:"file_size"`
+		LastModified int64  `json:"last_modified"`
+	} `json:"contents"`
+	NextPageToken string `json:"next_page_token"`
+}
+
+// ListVolumeDir lists the direct children of a Unity Catalog volume directory such as
+// /Volumes/main/ingest/landing through the Files API, following next_page_token until every
+// page has been read. Page requests are retried per the client's retry policy.
+func (c *DatabricksRESTClient) ListVolumeDir(ctx context.Context, volumePath string) ([]VolumeEntry, error) {
+	path, err := directoriesAPIPath(volumePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []VolumeEntry
+	var pageToken string
+	for {
+		pagePath := path
+		if pageToken != "" {
+			pagePath += "?" + url.Values{"page_token": {pageToken}}.Encode()
+		}
+		var page volumeDirPage
+		if err := c.doJSON(ctx, "GET", pagePath, nil, &page); err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", volumePath, err)
+		}
+
+		for _, e := range page.Contents {
+			entries = append(entries, VolumeEntry{
+				Path:         e.Path,
+				IsDir:        e.IsDirectory,
+				FileSize:     e.FileSize,
+				LastModified: epochMsToTime(e.LastModified),
+			})
+		}
+		if page.NextPageToken == "" {
+			return entries, nil
+		}
+		pageToken = page.NextPageToken
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+)
+
+// ErrVolumeFileExists is returned by UploadToVolume when the target exists and overwrite is false
+var ErrVolumeFileExists = errors.New("volume file already exists")
+
+// UploadToVolume streams a local file to a Unity Catalog volume path such as
+// /Volumes/main/ingest/landing/orders.parquet through the Files API. Without overwrite an
+// existing file is left untouched and ErrVolumeFileExists is returned. Uploads are not retried,
+// since the body is streamed from disk.
+func (c *DatabricksRESTClient) UploadToVolume(ctx context.Context, localPath, volumePath string, overwrite bool) error {
+	path, err := filesAPIPath(volumePath)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", localPath, err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", localPath, err)
+	}
+
+	query := url.Values{"overwrite": {fmt.Sprint(overwrite)}}
+	req, err := c.newRequest(ctx, http.MethodPut, path+"?"+query.Encode(), f)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = info.Size()
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := c.doHTTP(req)
+	if err != nil {
+		return fmt.Errorf("failed to upload %s to %s: %w", localPath, volumePath, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusConflict {
+		return fmt.Errorf("failed to upload %s: %w: %s", localPath, ErrVolumeFileExists, volumePath)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to upload %s to %s: %w", localPath, volumePath, newAPIError(resp.StatusCode, body))
+	}
+	return nil
+}
+
+// DownloadFromVolume streams a file from a Unity Catalog volume to localPath, replacing any
+// existing local file. A partially written file is removed if the download fails.
+func (c *DatabricksRESTClient) DownloadFromVolume(ctx context.Context, volumePath, localPath string) (err error) {
+	path, err := filesAPIPath(volumePath)
+	if err != nil {
+		return err
+	}
+
+	req, err := c.newRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.doHTTP(req)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", volumePath, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to download %s: %w", volumePath, newAPIError(resp.StatusCode, body))
+	}
+
+	f, err := os.Create(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", localPath, err)
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("failed to write %s: %w", localPath, closeErr)
+		}
+		if err != nil {
+			os.Remove(localPath)
+		}
+	}()
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		return fmt.Errorf("failed to download %s to %s: %w", volumePath, localPath, err)
+	}
+	return nil
+}
+
+// filesAPIPath maps a /Volumes/... path onto its Files API endpoint, escaping each segment
+func filesAPIPath(volumePath string) (string, error) {
+	segments, err := volumePathSegments(volumePath)
+	if err != nil {
+		return "", err
+	}
+	// Volumes/<catalog>/<schema>/<volume>/<file...>
+	if len(segments) < 5 {
+		return "", fmt.Errorf("volume path %q must name a file as /Volumes/<catalog>/<schema>/<volume>/<path>", volumePath)
+	}
+	return "/api/2.0/fs/files/" + strings.Join(segments, "/"), nil
+}
+
+// directoriesAPIPath maps a /Volumes/... directory onto its Files API directories endpoint. The
+// volume root itself is a directory.
+func directoriesAPIPath(volumePath string) (string, error) {
+	segments, err := volumePathSegments(strings.TrimSuffix(volumePath, "/"))
+	if err != nil {
+		return "", err
+	}
+	if len(segments) < 4 {
+		return "", fmt.Errorf("volume path %q must name a directory as /Volumes/<catalog>/<schema>/<volume>[/<path>]", volumePath)
+	}
+	return "/api/2.0/fs/directories/" + strings.Join(segments, "/"), nil
+}
+
+// volumePathSegments splits a /Volumes/... path into escaped segments, rejecting empty ones
+func volumePathSegments(volumePath string) ([]string, error) {
+	if !strings.HasPrefix(volumePath, "/Volumes/") {
+		return nil, fmt.Errorf("volume path %q must start with /Volumes/", volumePath)
+	}
+	segments := strings.Split(strings.TrimPrefix(volumePath, "/"), "/")
+	if slices.Contains(segments, "") {
+		return nil, fmt.Errorf("volume path %q has an empty segment", volumePath)
+	}
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return segments, nil
+}
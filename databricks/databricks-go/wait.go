@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+const (
+	// defaultPollInterval and maxPollInterval bound the warehouse state polls
+	defaultPollInterval = 1 * time.Second
+	maxPollInterval     = 10 * time.Second
+	cancelTimeout       = 10 * time.Second
+)
+
+// Default statement polling curve: fast first polls for short queries, backing off toward a cap
+// for long ones
+const (
+	defaultPollInitial    = 200 * time.Millisecond
+	defaultPollMax        = 5 * time.Second
+	defaultPollMultiplier = 1.5
+)
+
+// expectedDurationLead is the fraction of ExpectedDuration waited before the fast polls begin,
+// leaving room for the statement to run a little faster than last time
+const expectedDurationLead = 0.8
+
+// observedDurationsSize is how many statement texts ObservedDuration remembers
+const observedDurationsSize = 256
+
+// ObservedDuration returns how long the last successful run of the same statement text took on
+// this client, for seeding PollOptions.ExpectedDuration
+func (c *DatabricksRESTClient) ObservedDuration(statement string) (time.Duration, bool) {
+	d, ok := c.durations.get(statement)
+	if !ok {
+		return 0, false
+	}
+	return d.(time.Duration), true
+}
+
+// PollOptions shapes how WaitForStatementWithOptions polls. Zero fields use the defaults.
+type PollOptions struct {
+	// Initial is the first interval between polls; defaults to 200ms
+	Initial time.Duration
+	// Max caps the interval; defaults to 5s
+	Max time.Duration
+	// Multiplier grows the interval after each poll; defaults to 1.5
+	Multiplier float64
+	// ExpectedDuration, e.g. from ObservedDuration for the same statement text, skips the polls
+	// that would almost certainly find the statement still running: after the first poll, the
+	// next waits until 80% of ExpectedDuration has passed, then the curve starts from Initial
+	ExpectedDuration time.Duration
+}
+
+func (o PollOptions) withDefaults() PollOptions {
+	if o.Initial <= 0 {
+		o.Initial = defaultPollInitial
+	}
+	if o.Max <= 0 {
+		o.Max = defaultPollMax
+	}
+	o.Max = max(o.Max, o.Initial)
+	if o.Multiplier < 1 {
+		o.Multiplier = defaultPollMultiplier
+	}
+	return o
+}
+
+// WaitForStatement polls a statement until it reaches a terminal state (SUCCEEDED, FAILED,
+// CANCELED or CLOSED) or ctx is done. Polling starts at pollInterval, or 200ms when it is not
+// positive, and backs off by 1.5x per poll up to 5s. If ctx is canceled while the statement is
+// still running, the statement is canceled on the warehouse so it does not keep consuming compute.
+// A FAILED statement returns its timing together with a *StatementError.
+func (c *DatabricksRESTClient) WaitForStatement(ctx context.Context, statementID string, pollInterval time.Duration) (*TimingInfo, error) {
+	return c.WaitForStatementWithOptions(ctx, statementID, PollOptions{Initial: pollInterval})
+}
+
+// WaitForStatementWithOptions is WaitForStatement with a tunable polling curve
+func (c *DatabricksRESTClient) WaitForStatementWithOptions(ctx context.Context, statementID string, opts PollOptions) (*TimingInfo, error) {
+	startTime := time.Now()
+	resp, err := c.pollStatement(ctx, statementID, statementPath(statementID), opts)
+	if err != nil {
+		return nil, err
+	}
+	timing := newTimingInfo(resp, "", startTime, time.Now())
+	c.logger.DebugContext(ctx, "statement finished",
+		slog.String("statement_id", statementID),
+		slog.String("state", resp.Status.State),
+		slog.Int64("duration_ms", timing.DurationMs))
+	return timing, resp.statementError()
+}
+
+// pollStatement polls path until the statement reaches a terminal state and returns its final
+// response, canceling the statement if ctx is done first. A poll link in a response replaces
+// path for the next poll.
+func (c *DatabricksRESTClient) pollStatement(ctx context.Context, statementID, path string, opts PollOptions) (*StatementExecutionResponse, error) {
+	opts = opts.withDefaults()
+	startTime := time.Now()
+	interval := opts.Initial
+
+	for polls := 0; ; polls++ {
+		resp, err := c.getStatementAt(ctx, statementID, path)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, c.cancelAbandoned(ctx, statementID, err)
+			}
+			return nil, err
+		}
+
+		if isTerminalState(resp.Status.State) {
+			return resp, nil
+		}
+		path = resp.PollPath()
+
+		delay := interval
+		if polls == 0 && opts.ExpectedDuration > 0 {
+			lead := time.Duration(float64(opts.ExpectedDuration) * expectedDurationLead)
+			delay = max(delay, lead-time.Since(startTime))
+		} else {
+			interval = min(time.Duration(float64(interval)*opts.Multiplier), opts.Max)
+		}
+
+		select {
+		case <-ctx.Done():
+			err := fmt.Errorf("stopped waiting for statement %s in state %s: %w", statementID, resp.Status.State, ctx.Err())
+			return nil, c.cancelAbandoned(ctx, statementID, err)
+		case <-time.After(delay):
+		}
+	}
+}
+
+// cancelAbandoned cancels a statement whose waiter gave up. ctx is already done, so the cancel
+// request runs on a detached context with its own timeout.
+func (c *DatabricksRESTClient) cancelAbandoned(ctx context.Context, statementID string, waitErr error) error {
+	cancelCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cancelTimeout)
+	defer cancel()
+
+	if err := c.CancelStatement(cancelCtx, statementID); err != nil {
+		return errors.Join(waitErr, err)
+	}
+	return waitErr
+}
+
+func isTerminalState(state string) bool {
+	switch state {
+	case StateSucceeded, StateFailed, StateCanceled, StateClosed:
+		return true
+	}
+	return false
+}
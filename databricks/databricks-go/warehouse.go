@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// Warehouse states reported by /api/2.0/sql/warehouses/{id}
+const (
+	WarehouseStarting = "STARTING"
+	WarehouseRunning  = "RUNNING"
+	WarehouseStopping = "STOPPING"
+	WarehouseStopped  = "STOPPED"
+	WarehouseDeleting = "DELETING"
+	WarehouseDeleted  = "DELETED"
+)
+
+// queriesPerCluster is how many concurrent queries one warehouse cluster is sized to run
+// before further queries queue, per Databricks' scaling guidance
+const queriesPerCluster = 10
+
+// WarehouseInfo is the sizing and state of a SQL warehouse
+type WarehouseInfo struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	State          string `json:"state"`
+	ClusterSize    string `json:"cluster_size"`
+	MinNumClusters int    `json:"min_num_clusters"`
+	MaxNumClusters int    `json:"max_num_clusters"`
+	// AutoStopMins is the idle time before the warehouse stops; 0 disables auto-stop
+	AutoStopMins int `json:"auto_stop_mins"`
+}
+
+// SuggestedConcurrency returns the number of concurrent queries the warehouse can run at its
+// maximum scale without queuing: 10 per cluster
+func (w WarehouseInfo) SuggestedConcurrency() int {
+	return max(w.MaxNumClusters, 1) * queriesPerCluster
+}
+
+// GetWarehouse returns a warehouse's name, state, size and scaling limits
+func (c *DatabricksRESTClient) GetWarehouse(ctx context.Context, warehouseID string) (*WarehouseInfo, error) {
+	var info WarehouseInfo
+	if err := c.doJSON(ctx, "GET", "/api/2.0/sql/warehouses/"+warehouseID, nil, &info); err != nil {
+		return nil, fmt.Errorf("failed to get warehouse %s: %w", warehouseID, err)
+	}
+	return &info, nil
+}
+
+// SuggestedConcurrency returns the SuggestedConcurrency of the client's warehouse
+func (c *DatabricksRESTClient) SuggestedConcurrency(ctx context.Context) (int, error) {
+	info, err := c.GetWarehouse(ctx, c.warehouseID)
+	if err != nil {
+		return 0, err
+	}
+	return info.SuggestedConcurrency(), nil
+}
+
+// StartWarehouse asks a stopped warehouse to start. It returns once the request is accepted;
+// use WaitForWarehouseRunning to wait for the warehouse itself.
+func (c *DatabricksRESTClient) StartWarehouse(ctx context.Context, warehouseID string) error {
+	if err := c.doJSON(ctx, "POST", "/api/2.0/sql/warehouses/"+warehouseID+"/start", nil, nil); err != nil {
+		return fmt.Errorf("failed to start warehouse %s: %w", warehouseID, err)
+	}
+	return nil
+}
+
+// WaitForWarehouseRunning polls a warehouse until it is RUNNING, backing off from 1s to 10s
+// between polls. It fails if the warehouse is being deleted or timeout elapses first.
+func (c *DatabricksRESTClient) WaitForWarehouseRunning(ctx context.Context, warehouseID string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	interval := defaultPollInterval
+	for {
+		state, err := c.warehouseState(ctx, warehouseID)
+		if err != nil {
+			return err
+		}
+		switch state {
+		case WarehouseRunning:
+			return nil
+		case WarehouseDeleting, WarehouseDeleted:
+			return fmt.Errorf("warehouse %s is %s", warehouseID, state)
+		}
+
+		if err := sleepContext(ctx, interval); err != nil {
+			return fmt.Errorf("warehouse %s not running after %s (state %s): %w", warehouseID, timeout, state, err)
+		}
+		interval = min(interval*2, maxPollInterval)
+	}
+}
+
+// EnsureWarehouseRunning starts a warehouse if needed and waits for it to be RUNNING, returning
+// how long the start took so cold-start latency can be reported separately from query timing.
+// A warehouse that is already running returns immediately with zero elapsed time.
+func (c *DatabricksRESTClient) EnsureWarehouseRunning(ctx context.Context, warehouseID string, timeout time.Duration) (time.Duration, error) {
+	state, err := c.warehouseState(ctx, warehouseID)
+	if err != nil {
+		return 0, err
+	}
+	if state == WarehouseRunning {
+		return 0, nil
+	}
+
+	startTime := time.Now()
+	if state != WarehouseStarting {
+		if err := c.StartWarehouse(ctx, warehouseID); err != nil {
+			return 0, err
+		}
+	}
+	if err := c.WaitForWarehouseRunning(ctx, warehouseID, timeout); err != nil {
+		return time.Since(startTime), err
+	}
+
+	elapsed := time.Since(startTime)
+	c.logger.DebugContext(ctx, "warehouse started",
+		slog.String("warehouse_id", warehouseID),
+		slog.Int64("duration_ms", elapsed.Milliseconds()))
+	return elapsed, nil
+}
+
+// ResolveWarehouseID looks up the ID of the warehouse whose display name is exactly name. It
+// fails when no warehouse or more than one has that name.
+func (c *DatabricksRESTClient) ResolveWarehouseID(ctx context.Context, name string) (string, error) {
+	var resp struct {
+		Warehouses []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"warehouses"`
+	}
+	if err := c.doJSON(ctx, "GET", "/api/2.0/sql/warehouses", nil, &resp); err != nil {
+		return "", fmt.Errorf("failed to list warehouses: %w", err)
+	}
+
+	var ids []string
+	for _, w := range resp.Warehouses {
+		if w.Name == name {
+			ids = append(ids, w.ID)
+		}
+	}
+	switch len(ids) {
+	case 0:
+		return "", fmt.Errorf("no warehouse named %q", name)
+	case 1:
+		return ids[0], nil
+	default:
+		return "", fmt.Errorf("%d warehouses are named %q (%s); use the warehouse ID", len(ids), name, strings.Join(ids, ", "))
+	}
+}
+
+// warehouseState returns the current state of a warehouse
+func (c *DatabricksRESTClient) warehouseState(ctx context.Context, warehouseID string) (string, error) {
+	info, err := c.GetWarehouse(ctx, warehouseID)
+	if err != nil {
+		return "", err
+	}
+	return info.State, nil
+}